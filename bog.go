@@ -2,9 +2,12 @@ package bog
 
 import (
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -14,6 +17,8 @@ type Archive struct {
 	dev    bool
 	isFile bool
 	root   string
+	// fsys is the file system read on every call by archives created with WrapFS.
+	fsys fs.FS
 }
 
 // NewArchive creates new Archive. This function is called by the generator.
@@ -37,25 +42,37 @@ func (a *Archive) SetRoot(path string) {
 // If there is an error, it will be of type *PathError
 func (a *Archive) Open(name string) (File, error) {
 	name = a.formatName(name)
+	if logical, ok := a.unfingerprint(name); ok {
+		name = logical
+	}
 	if a.dev {
 		return os.Open(filepath.Join(a.root, name))
 	}
+	if a.fsys != nil {
+		return openFS(a.fsys, fsName(name))
+	}
 	f, ok := a.files["/"+name]
 	if !ok {
-		return nil, &os.PathError{"open", name, errNotFound}
+		return nil, &os.PathError{Op: "open", Path: name, Err: errNotFound}
 	}
-	return f, nil
+	return reopen(f), nil
 }
 
 // Stat returns a FileInfo describing the named file. If there is an error, it will be of type *PathError.
 func (a *Archive) Stat(name string) (fi os.FileInfo, err error) {
 	name = a.formatName(name)
+	if logical, ok := a.unfingerprint(name); ok {
+		name = logical
+	}
 	if a.dev {
 		return os.Stat(filepath.Join(a.root, name))
 	}
+	if a.fsys != nil {
+		return fs.Stat(a.fsys, fsName(name))
+	}
 	f, ok := a.files["/"+name]
 	if !ok {
-		return nil, &os.PathError{"stat", name, errNotFound}
+		return nil, &os.PathError{Op: "stat", Path: name, Err: errNotFound}
 	}
 	return f.Stat()
 }
@@ -66,11 +83,14 @@ func (a *Archive) ReadDir(dirname string) ([]os.FileInfo, error) {
 	if a.dev {
 		return ioutil.ReadDir(filepath.Join(a.root, dirname))
 	}
+	if a.fsys != nil {
+		return readDirFS(a.fsys, fsName(dirname))
+	}
 	f, ok := a.files["/"+dirname]
 	if !ok {
-		return nil, &os.PathError{"open", dirname, errNotFound}
+		return nil, &os.PathError{Op: "open", Path: dirname, Err: errNotFound}
 	}
-	return f.Readdir(-1)
+	return reopen(f).Readdir(-1)
 }
 
 // ReadFile reads the file named by filename and returns the contents. A successful call returns err == nil, not err == EOF. 
@@ -84,22 +104,86 @@ func (a *Archive) ReadFile(name string) ([]byte, error) {
 	return ioutil.ReadAll(f)
 }
 
-// Extract extracts the content of the archive to current folder.
+// gzipped returns the gzip compressed content of the named file, if it was stored by the generator.
+func (a *Archive) gzipped(name string) (string, bool) {
+	if a.dev {
+		return "", false
+	}
+	f, ok := a.files["/"+a.formatName(name)]
+	if !ok {
+		return "", false
+	}
+	bf, ok := f.(*bogFile)
+	if !ok || bf.gzip == "" {
+		return "", false
+	}
+	return bf.gzip, true
+}
+
+// Walk walks the file tree rooted at root, calling walkFn for each file or folder in the tree, including root.
+// The files are walked in lexical order. Paths passed to walkFn are slash separated and start with "/".
+// Like filepath.Walk, walkFn may return filepath.SkipDir to skip the content of a folder.
+func (a *Archive) Walk(root string, walkFn filepath.WalkFunc) error {
+	name := "/" + a.formatName(root)
+	info, err := a.Stat(root)
+	if err != nil {
+		err = walkFn(name, nil, err)
+	} else {
+		err = a.walk(name, info, walkFn)
+	}
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+func (a *Archive) walk(name string, info os.FileInfo, walkFn filepath.WalkFunc) error {
+	err := walkFn(name, info, nil)
+	if err != nil || !info.IsDir() {
+		return err
+	}
+	infos, err := a.ReadDir(name)
+	if err != nil {
+		return walkFn(name, info, err)
+	}
+	sort.Sort(byName(infos))
+	for _, child := range infos {
+		err = a.walk(path.Join(name, child.Name()), child, walkFn)
+		if err != nil && (err != filepath.SkipDir || !child.IsDir()) {
+			return err
+		}
+	}
+	return nil
+}
+
+type byName []os.FileInfo
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Less(i, j int) bool { return s[i].Name() < s[j].Name() }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Extract extracts the content of the archive to current folder. The modes of folders are set once
+// their files are written, so that read-only folders can be extracted, and the mode of current folder
+// is left unchanged.
 func (a *Archive) Extract() error {
-	var outputPrefix = "."
-	if !a.isFile {
-		root := filepath.Base(a.root)
-		if root != "." && root != ".." {
-			err := os.RemoveAll(root)
-			if err != nil {
-				return err
-			}
-			outputPrefix = root
+	outputPrefix := a.outputPrefix()
+	if !a.isFile && outputPrefix != "." {
+		err := os.RemoveAll(outputPrefix)
+		if err != nil {
+			return err
+		}
+	}
+	files := a.files
+	if a.fsys != nil {
+		var err error
+		files, err = a.filesFS()
+		if err != nil {
+			return err
 		}
-	} else {
-		outputPrefix = filepath.Base(a.root)
 	}
-	for path, f := range a.files {
+	folders := make(map[string]os.FileMode)
+	for path, f := range files {
+		f = reopen(f)
 		stat, err := f.Stat()
 		if err != nil {
 			return err
@@ -119,6 +203,7 @@ func (a *Archive) Extract() error {
 			}
 			defer newFile.Close()
 			_, err = io.Copy(newFile, f)
+			f.Close()
 			if err != nil {
 				return err
 			}
@@ -132,15 +217,29 @@ func (a *Archive) Extract() error {
 			if err != nil {
 				return err
 			}
-			err = os.Chmod(folder, stat.Mode())
-			if err != nil {
-				return err
+			if folder != "." {
+				folders[folder] = stat.Mode()
 			}
 		}
 	}
+	for folder, mode := range folders {
+		err := os.Chmod(folder, mode)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// outputPrefix returns the folder, or the file name for a single file, where the archive is extracted.
+func (a *Archive) outputPrefix() string {
+	root := filepath.Base(a.root)
+	if !a.isFile && (root == "." || root == "..") {
+		return "."
+	}
+	return root
+}
+
 func (a *Archive) formatName(name string) string {
 	if a.isFile {
 		return ""
@@ -0,0 +1,88 @@
+package bog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpenReturnsNewHandles(t *testing.T) {
+	a := testArchive()
+	f1, err := a.Open("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := a.Open("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 2)
+	_, err = f1.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f1.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reading from and closing f1 does not move nor close f2.
+	data, err := ioutil.ReadAll(f2)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("second handle read %q, %v, expected %q", data, err, "hello")
+	}
+	_, err = f1.Read(b)
+	if err == nil {
+		t.Errorf("read from a closed handle succeeded")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	a := testArchive()
+	tests := []struct {
+		root     string
+		skip     string
+		expected []string
+	}{
+		{"/", "", []string{"/", "/a.txt", "/empty", "/sub", "/sub/b.txt"}},
+		{"/", "/sub", []string{"/", "/a.txt", "/empty", "/sub"}},
+		{"sub", "", []string{"/sub", "/sub/b.txt"}},
+		{"/sub/b.txt", "", []string{"/sub/b.txt"}},
+		{"/sub", "/sub", []string{"/sub"}},
+	}
+	for _, test := range tests {
+		var walked []string
+		err := a.Walk(test.root, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			walked = append(walked, filepath.Clean(name))
+			if name == test.skip {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Walk(%q) returned %v", test.root, err)
+		}
+		if !reflect.DeepEqual(walked, test.expected) {
+			t.Errorf("Walk(%q) skipping %q walked %v, expected %v", test.root, test.skip, walked, test.expected)
+		}
+	}
+}
+
+func TestWalkMissingRoot(t *testing.T) {
+	a := testArchive()
+	called := false
+	err := a.Walk("/missing", func(name string, info os.FileInfo, err error) error {
+		called = true
+		if _, ok := err.(*os.PathError); name != "/missing" || info != nil || !ok {
+			t.Errorf("walkFn(%q, %v, %v), expected a path error for /missing", name, info, err)
+		}
+		return err
+	})
+	if !called || err == nil {
+		t.Errorf("Walk of a missing root returned %v", err)
+	}
+}
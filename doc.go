@@ -29,6 +29,16 @@ Or shorter:
   bog a /path/to/directory
   bog a /path/to/file
 
+Archiving tar and zip files
+
+Files can also be archived from a tar file, possibly compressed with gzip, or a zip file.
+Modes and modification times are taken from the headers of the entries:
+  bog archive dist.tar.gz
+  bog archive dist.zip
+
+Use "-" to read a tar stream from stdin. In this case, the name of the archive is the package name:
+  tar c -C dist . | bog -p assets archive -
+
 Extracting
 
 To extract the data of archived source file to current directory:
@@ -37,11 +47,22 @@ To extract the data of archived source file to current directory:
 Or:
   bog e directory-archive.go
 
+To write the content as a tar or zip file instead, use -format and -o switches:
+  bog extract -format tar -o directory.tar directory-archive.go
+
+If the source file contains several archives, choose the one to extract with -var switch:
+  bog extract -var MyFolderArchive directory-archive.go
+
 Ignore files
 
 Bog supports the use of special file called .bogignore to make the generator ignore certain files
 or folders. It is nearly identical to .gitignore.
 
+The rules of .bogignore files found in tar and zip files apply in the same way. Additional rules can
+be read from a file with -i switch:
+
+  bog -i my-ignore-rules a /path/to/directory
+
 Setting package name
 
 By default, bog will use current directory for package name in generated files. To change that, use -p switch:
@@ -77,6 +98,95 @@ To list all files in a folder, use ReadDir method, which is identical with iouti
    fi, err := MyFolderArchive.ReadDir("path/to/subfolder")
    fi, err := MyFolderArchive.ReadDir("") // will list files from root folder.
 
+Walk through files
+
+To visit every file and folder in the archive, use Walk method, which is identical with filepath.Walk:
+   err := MyFolderArchive.Walk("", func(path string, info os.FileInfo, err error) error {
+   	fmt.Println(path, info.Size())
+   	return err
+   })
+
+Parse templates
+
+ParseHTMLTemplates and ParseTextTemplates parse the files matched by patterns as html/template or
+text/template templates. Patterns are matched against paths relative to the root of the archive, which
+are also the names of the templates. The FuncMap may be nil:
+   views, err := MyFolderArchive.ParseHTMLTemplates(template.FuncMap{"upper": strings.ToUpper}, "views/*.html")
+   err = views.ExecuteTemplate(w, "views/index.html", data)
+
+In development mode, the templates are parsed again when the files change, so there is no need to
+restart the program. The files are checked at most once per second. Template method returns the
+current *template.Template.
+
+Content hashes
+
+A SHA-256 hash of every file is computed when the archive is generated. It can be used as a strong ETag
+or as a cache key:
+   hash, err := MyFolderArchive.Hash("path/to/my-file")
+
+To check at startup that the archived files were not corrupted or patched, use Verify method. It computes
+the hashes again, and returns an error of type *IntegrityError listing mismatched files:
+   err := MyFolderArchive.Verify()
+
+Content types
+
+The MIME type of every file is also recorded when the archive is generated, from its extension or,
+for unknown extensions, from its first bytes. Server sends it as Content-Type. In development mode,
+ContentType method guesses it in the same way from the real file:
+   ctype, err := MyFolderArchive.ContentType("path/to/my-file")
+
+To choose the MIME type of unusual extensions, put a .bogmime file at the root of the archived folder,
+with one extension and its MIME type per line. Like .bogignore, it is not archived:
+   .glsl text/plain; charset=utf-8
+   .usdz model/vnd.usdz+zip
+
+Fingerprinted names
+
+For long-term HTTP caching, Fingerprint method returns a name containing a part of the content hash,
+for example "/static/app.3f9a1c2b.js" for "/static/app.js". Open and Stat accept both names:
+   url := MyFolderArchive.Fingerprint("/static/app.js")
+
+Use -f switch to also write a JSON manifest mapping logical names to fingerprinted names, next to
+the generated file, so that other tools can read it:
+   bog -f a /path/to/directory
+
+Serving over HTTP
+
+Server type serves the files of an archive over HTTP. Content hashes are sent as ETag, and
+fingerprinted names are served with immutable Cache-Control headers:
+   http.Handle("/static/", http.StripPrefix("/static", bog.NewServer(MyFolderArchive)))
+
+Server has options for single page applications, custom error pages, directory listing and dotfiles:
+   s := bog.NewServer(MyFolderArchive)
+   s.Fallback = "/index.html" // served for missing paths without extension
+   s.NotFound = "/404.html"   // served with status 404 for other missing files
+   s.ListDirs = true          // list folders without index.html
+   s.HideDotfiles = true      // make dotfiles unreachable
+
+Use -z switch to store a gzip compressed variant of compressible files when archiving. Server then sends
+the compressed bytes with "Content-Encoding: gzip" to clients accepting it, instead of compressing
+the same file on every request:
+   bog -z a /path/to/directory
+
+Export as tar or zip
+
+The content of the archive can be written as a tar or zip file, for example to offer a download
+of bundled resources. Modes, modification times and folders are preserved:
+   err := MyFolderArchive.WriteTar(w)
+   err := MyFolderArchive.WriteZip(w)
+
+Archives from file systems
+
+An Archive can also be created at runtime from any fs.FS, for example an embed.FS, a fstest.MapFS,
+a *zip.Reader or a folder. FromFS and FromDir take a snapshot, reading every file in memory: Stat and
+ReadDir describe the files as they were, and Extract writes the snapshot. WrapFS reads nothing beforehand:
+Stat, ReadDir and Open go to the file system on every call, and Extract walks its current files:
+   a, err := bog.FromFS(fstest.MapFS{"index.html": {Data: []byte("<h1>Hi</h1>")}})
+   a, err := bog.FromDir("public")
+   a := bog.WrapFS(zipReader)
+
+Hashes are computed from the content, and Verify always succeeds.
+
 Development mode
 
 Bog supports development mode, in which bog will not archive files, and read data from the real files directly.
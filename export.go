@@ -0,0 +1,95 @@
+package bog
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+)
+
+// WriteTar writes the content of the archive to w in tar format. Files and folders are
+// named as they would be by Extract, and keep their modes and modification times.
+func (a *Archive) WriteTar(w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := a.Walk("", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		exportName, ok := a.exportName(name, info)
+		if !ok {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = exportName
+		err = tw.WriteHeader(header)
+		if err != nil || info.IsDir() {
+			return err
+		}
+		return a.copyFile(tw, name)
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// WriteZip writes the content of the archive to w in zip format. Files and folders are
+// named as they would be by Extract, and keep their modes and modification times.
+func (a *Archive) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	err := a.Walk("", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		exportName, ok := a.exportName(name, info)
+		if !ok {
+			return nil
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = exportName
+		if info.IsDir() {
+			header.Method = zip.Store
+		} else {
+			header.Method = zip.Deflate
+		}
+		fw, err := zw.CreateHeader(header)
+		if err != nil || info.IsDir() {
+			return err
+		}
+		return a.copyFile(fw, name)
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// exportName returns the name of an exported file. Folder names end with a slash.
+// It returns false for the root folder when the archive is not extracted to a folder of its own.
+func (a *Archive) exportName(name string, info os.FileInfo) (string, bool) {
+	exportName := path.Join(a.outputPrefix(), name)
+	if exportName == "." {
+		return "", false
+	}
+	if info.IsDir() {
+		exportName += "/"
+	}
+	return exportName, true
+}
+
+func (a *Archive) copyFile(w io.Writer, name string) error {
+	f, err := a.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
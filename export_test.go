@@ -0,0 +1,105 @@
+package bog
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// exported describes an exported file or folder.
+type exported struct {
+	mode    os.FileMode
+	content string
+}
+
+var expectedExport = map[string]exported{
+	"assets/":          {os.ModeDir | 0755, ""},
+	"assets/a.txt":     {0644, "hello"},
+	"assets/empty/":    {os.ModeDir | 0755, ""},
+	"assets/sub/":      {os.ModeDir | 0755, ""},
+	"assets/sub/b.txt": {0600, "world!"},
+}
+
+func TestWriteTar(t *testing.T) {
+	var buf bytes.Buffer
+	err := testArchive().WriteTar(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]exported)
+	tr := tar.NewReader(&buf)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !header.ModTime.Equal(testModTime) {
+			t.Errorf("%s: modification time %v, expected %v", header.Name, header.ModTime, testModTime)
+		}
+		files[header.Name] = exported{header.FileInfo().Mode(), string(data)}
+	}
+	if !reflect.DeepEqual(files, expectedExport) {
+		t.Errorf("tar holds %v, expected %v", files, expectedExport)
+	}
+}
+
+func TestWriteZip(t *testing.T) {
+	var buf bytes.Buffer
+	err := testArchive().WriteZip(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]exported)
+	for _, zf := range zr.File {
+		r, err := zf.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !zf.Modified.Equal(testModTime) {
+			t.Errorf("%s: modification time %v, expected %v", zf.Name, zf.Modified, testModTime)
+		}
+		files[zf.Name] = exported{zf.Mode(), string(data)}
+	}
+	if !reflect.DeepEqual(files, expectedExport) {
+		t.Errorf("zip holds %v, expected %v", files, expectedExport)
+	}
+}
+
+func TestWriteTarSingleFile(t *testing.T) {
+	f := NewBogFile([]byte("hello"), &FileInfo{FileName: "a.txt", FileSize: 5, FileMode: 0644, FileModTime: testModTime})
+	a := NewArchive(map[string]File{"/": f}, false, true, "a.txt")
+	var buf bytes.Buffer
+	err := a.WriteTar(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(&buf)
+	header, err := tr.Next()
+	if err != nil || header.Name != "a.txt" {
+		t.Fatalf("first tar entry %v, %v, expected a.txt", header, err)
+	}
+	if _, err = tr.Next(); err != io.EOF {
+		t.Errorf("tar of a single file has more entries")
+	}
+}
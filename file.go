@@ -1,10 +1,10 @@
 package bog
 
 import (
-	"bytes"
 	"errors"
 	"io"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -33,6 +33,10 @@ type FileInfo struct {
 	FileSize    int64
 	FileMode    os.FileMode
 	FileModTime time.Time
+	// FileHash is the hex encoded SHA-256 hash of the content, empty for folders.
+	FileHash string
+	// FileContentType is the MIME type of the content detected by the generator, empty for folders.
+	FileContentType string
 }
 
 // Name returns base name of the file.
@@ -60,14 +64,20 @@ func (fi *FileInfo) IsDir() bool {
 	return fi.FileMode.IsDir()
 }
 
+// ContentType returns the MIME type recorded at generation time, or an empty string if unknown.
+func (fi *FileInfo) ContentType() string {
+	return fi.FileContentType
+}
+
 // Sys is always nil
 func (fi *FileInfo) Sys() interface{} {
 	return nil
 }
 
 type bogFile struct {
-	data     []byte
-	r        *bytes.Reader
+	data     string
+	gzip     string
+	r        *strings.Reader
 	stat     os.FileInfo
 	children []File
 	off      int
@@ -76,9 +86,10 @@ type bogFile struct {
 
 // NewBogFile creates a File. Use internally by generator.
 func NewBogFile(data []byte, info os.FileInfo) File {
+	s := string(data)
 	return &bogFile{
-		data: data,
-		r:    bytes.NewReader(data),
+		data: s,
+		r:    strings.NewReader(s),
 		stat: info,
 	}
 }
@@ -91,6 +102,25 @@ func NewBogFolder(children []File, info os.FileInfo) File {
 	}
 }
 
+// reopen returns a new handle on f when f is an archived file, so that reading from
+// the returned file does not disturb the other handles.
+func reopen(f File) File {
+	if ff, ok := f.(*fsFile); ok {
+		return &fsFile{fsys: ff.fsys, name: ff.name, info: ff.info}
+	}
+	bf, ok := f.(*bogFile)
+	if !ok {
+		return f
+	}
+	return &bogFile{
+		data:     bf.data,
+		gzip:     bf.gzip,
+		r:        strings.NewReader(bf.data),
+		stat:     bf.stat,
+		children: bf.children,
+	}
+}
+
 func (f *bogFile) Close() error {
 	f.closed = true
 	f.off = 0
@@ -103,36 +133,39 @@ func (f *bogFile) Name() string {
 
 func (f *bogFile) Read(b []byte) (n int, err error) {
 	if f.closed {
-		return 0, &os.PathError{"read", f.Name(), errBadFileDescriptor}
+		return 0, &os.PathError{Op: "read", Path: f.Name(), Err: errBadFileDescriptor}
 	}
 	if f.stat.IsDir() {
-		return 0, &os.PathError{"read", f.Name(), errIsDirectory}
+		return 0, &os.PathError{Op: "read", Path: f.Name(), Err: errIsDirectory}
 	}
 	return f.r.Read(b)
 }
 
 func (f *bogFile) ReadAt(b []byte, off int64) (n int, err error) {
 	if f.closed {
-		return 0, &os.PathError{"read", f.Name(), errBadFileDescriptor}
+		return 0, &os.PathError{Op: "read", Path: f.Name(), Err: errBadFileDescriptor}
 	}
 	if f.stat.IsDir() {
-		return 0, &os.PathError{"read", f.Name(), errIsDirectory}
+		return 0, &os.PathError{Op: "read", Path: f.Name(), Err: errIsDirectory}
 	}
 	return f.r.ReadAt(b, off)
 }
 
 func (f *bogFile) Readdir(n int) (fi []os.FileInfo, err error) {
 	if f.closed {
-		return nil, &os.PathError{"readdirent", f.Name(), errBadFileDescriptor}
+		return nil, &os.PathError{Op: "readdirent", Path: f.Name(), Err: errBadFileDescriptor}
 	}
 	if !f.stat.IsDir() {
-		return nil, &os.PathError{"readdirent", f.Name(), errInvalid}
+		return nil, &os.PathError{Op: "readdirent", Path: f.Name(), Err: errInvalid}
 	}
 	if f.off >= len(f.children) {
+		if n <= 0 {
+			return []os.FileInfo{}, nil
+		}
 		return nil, io.EOF
 	}
 	var children []File
-	if n < 0 || f.off+n >= len(f.children) {
+	if n <= 0 || f.off+n >= len(f.children) {
 		children = f.children[f.off:]
 		f.off = len(f.children)
 	} else {
@@ -151,10 +184,10 @@ func (f *bogFile) Readdir(n int) (fi []os.FileInfo, err error) {
 
 func (f *bogFile) Readdirnames(n int) (names []string, err error) {
 	if f.closed {
-		return nil, &os.PathError{"readdirent", f.Name(), errBadFileDescriptor}
+		return nil, &os.PathError{Op: "readdirent", Path: f.Name(), Err: errBadFileDescriptor}
 	}
 	if !f.stat.IsDir() {
-		return nil, &os.PathError{"readdirent", f.Name(), errInvalid}
+		return nil, &os.PathError{Op: "readdirent", Path: f.Name(), Err: errInvalid}
 	}
 	children, err := f.Readdir(n)
 	if err != nil {
@@ -168,17 +201,17 @@ func (f *bogFile) Readdirnames(n int) (names []string, err error) {
 
 func (f *bogFile) Seek(offset int64, whence int) (ret int64, err error) {
 	if f.closed {
-		return 0, &os.PathError{"seek", f.Name(), errBadFileDescriptor}
+		return 0, &os.PathError{Op: "seek", Path: f.Name(), Err: errBadFileDescriptor}
 	}
 	if f.stat.IsDir() {
-		return 0, &os.PathError{"seek", f.Name(), errIsDirectory}
+		return 0, &os.PathError{Op: "seek", Path: f.Name(), Err: errIsDirectory}
 	}
 	return f.r.Seek(offset, whence)
 }
 
 func (f *bogFile) Stat() (fi os.FileInfo, err error) {
 	if f.closed {
-		return nil, &os.PathError{"stat", f.Name(), errBadFileDescriptor}
+		return nil, &os.PathError{Op: "stat", Path: f.Name(), Err: errBadFileDescriptor}
 	}
 	return f.stat, nil
 }
@@ -0,0 +1,102 @@
+package bog
+
+import (
+	"io"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+var testModTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// testArchive returns an archive of a folder holding a.txt, an empty folder and sub/b.txt.
+func testArchive() *Archive {
+	a := NewBogFile([]byte("hello"), &FileInfo{FileName: "a.txt", FileSize: 5, FileMode: 0644, FileModTime: testModTime})
+	empty := NewBogFolder(nil, &FileInfo{FileName: "empty", FileMode: os.ModeDir | 0755, FileModTime: testModTime})
+	b := NewBogFile([]byte("world!"), &FileInfo{FileName: "b.txt", FileSize: 6, FileMode: 0600, FileModTime: testModTime})
+	sub := NewBogFolder([]File{b}, &FileInfo{FileName: "sub", FileMode: os.ModeDir | 0755, FileModTime: testModTime})
+	root := NewBogFolder([]File{a, empty, sub}, &FileInfo{FileName: "assets", FileMode: os.ModeDir | 0755, FileModTime: testModTime})
+	return NewArchive(map[string]File{
+		"/":          root,
+		"/a.txt":     a,
+		"/empty":     empty,
+		"/sub":       sub,
+		"/sub/b.txt": b,
+	}, false, false, "assets")
+}
+
+func readdirNames(t *testing.T, f File, n int) ([]string, error) {
+	infos, err := f.Readdir(n)
+	if err == nil && infos == nil {
+		t.Errorf("Readdir(%d) returned a nil slice without error", n)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names, err
+}
+
+func TestReaddir(t *testing.T) {
+	a := testArchive()
+	for _, n := range []int{-1, 0} {
+		f, err := a.Open("/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := readdirNames(t, f, n)
+		if err != nil || len(names) != 3 {
+			t.Errorf("Readdir(%d) = %v, %v, expected 3 names", n, names, err)
+		}
+		// Like os.File, an exhausted folder returns an empty list when reading all entries.
+		names, err = readdirNames(t, f, n)
+		if err != nil || len(names) != 0 {
+			t.Errorf("Readdir(%d) of an exhausted folder = %v, %v, expected no names", n, names, err)
+		}
+	}
+
+	f, err := a.Open("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"a.txt", "empty", "sub"} {
+		names, err := readdirNames(t, f, 1)
+		if err != nil || len(names) != 1 || names[0] != expected {
+			t.Errorf("Readdir(1) = %v, %v, expected [%s]", names, err, expected)
+		}
+	}
+	_, err = f.Readdir(1)
+	if err != io.EOF {
+		t.Errorf("Readdir(1) of an exhausted folder returned %v, expected io.EOF", err)
+	}
+}
+
+func TestReadDirEmptyFolder(t *testing.T) {
+	a := testArchive()
+	infos, err := a.ReadDir("/empty")
+	if err != nil || len(infos) != 0 {
+		t.Errorf("ReadDir of an empty folder = %v, %v", infos, err)
+	}
+	var walked []string
+	err = a.Walk("/", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		walked = append(walked, path.Clean(name))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"/", "/a.txt", "/empty", "/sub", "/sub/b.txt"}
+	if len(walked) != len(expected) {
+		t.Fatalf("walked %v, expected %v", walked, expected)
+	}
+	for i := range expected {
+		if walked[i] != expected[i] {
+			t.Errorf("walked %v, expected %v", walked, expected)
+			break
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package bog
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FingerprintLen is the number of hex characters of the content hash inserted in fingerprinted names.
+const FingerprintLen = 8
+
+// FingerprintName inserts the first FingerprintLen characters of hash before the extension of name,
+// "/static/app.js" becoming "/static/app.3f9a1c2b.js".
+func FingerprintName(name string, hash string) string {
+	if len(hash) > FingerprintLen {
+		hash = hash[:FingerprintLen]
+	}
+	ext := path.Ext(name)
+	if ext == path.Base(name) {
+		ext = ""
+	}
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// Fingerprint returns the fingerprinted name of the named file, which contains a part of its content hash.
+// Fingerprinted names change whenever the content changes, so they can be cached forever. Open and Stat
+// accept both the fingerprinted and the logical names. If the file does not exist or is a folder,
+// name is returned unchanged.
+func (a *Archive) Fingerprint(name string) string {
+	fi, err := a.Stat(name)
+	if err != nil || fi.IsDir() {
+		return name
+	}
+	hash, err := a.Hash(name)
+	if err != nil {
+		return name
+	}
+	return FingerprintName(name, hash)
+}
+
+// unfingerprint returns the logical name of name if name is a fingerprinted name
+// of an archived file, and not the name of an archived file itself.
+func (a *Archive) unfingerprint(name string) (string, bool) {
+	if a.isFile || a.exists(name) {
+		return "", false
+	}
+	ext := path.Ext(name)
+	if ext == path.Base(name) {
+		ext = ""
+	}
+	base := strings.TrimSuffix(name, ext)
+	hashExt := path.Ext(base)
+	if len(hashExt) != FingerprintLen+1 || !isHex(hashExt[1:]) {
+		return "", false
+	}
+	logical := strings.TrimSuffix(base, hashExt) + ext
+	if !a.exists(logical) {
+		return "", false
+	}
+	hash, err := a.Hash(logical)
+	if err != nil || !strings.HasPrefix(hash, hashExt[1:]) {
+		return "", false
+	}
+	return logical, true
+}
+
+func (a *Archive) exists(name string) bool {
+	if a.dev {
+		_, err := os.Stat(filepath.Join(a.root, name))
+		return err == nil
+	}
+	if a.fsys != nil {
+		_, err := fs.Stat(a.fsys, fsName(name))
+		return err == nil
+	}
+	_, ok := a.files["/"+name]
+	return ok
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package bog
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestFingerprintName(t *testing.T) {
+	hash := sha256Hex("x")
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"/static/app.js", "/static/app." + hash[:FingerprintLen] + ".js"},
+		{"/static/app.min.js", "/static/app.min." + hash[:FingerprintLen] + ".js"},
+		{"/LICENSE", "/LICENSE." + hash[:FingerprintLen]},
+		{"/.env", "/.env." + hash[:FingerprintLen]},
+	}
+	for _, test := range tests {
+		if got := FingerprintName(test.name, hash); got != test.expected {
+			t.Errorf("FingerprintName(%q) = %q, expected %q", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := testArchive()
+	name := a.Fingerprint("/sub/b.txt")
+	expected := FingerprintName("/sub/b.txt", sha256Hex("world!"))
+	if name != expected {
+		t.Fatalf("Fingerprint = %q, expected %q", name, expected)
+	}
+	data, err := a.ReadFile(name)
+	if err != nil || string(data) != "world!" {
+		t.Errorf("reading %s returned %q, %v", name, data, err)
+	}
+	if _, err = a.Stat(name); err != nil {
+		t.Errorf("Stat(%q) returned %v", name, err)
+	}
+	// A fingerprint which is not the one of the content is not accepted.
+	wrong := FingerprintName("/sub/b.txt", sha256Hex("other"))
+	if _, err = a.Open(wrong); err == nil {
+		t.Errorf("Open(%q) with a wrong fingerprint succeeded", wrong)
+	}
+	for _, name := range []string{"/sub", "/missing.txt"} {
+		if got := a.Fingerprint(name); got != name {
+			t.Errorf("Fingerprint(%q) = %q, expected the name unchanged", name, got)
+		}
+	}
+}
+
+func TestFingerprintDevelopmentMode(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "app.js", "console.log(1)")
+	a := NewArchive(nil, true, false, dir)
+	name := a.Fingerprint("/app.js")
+	if name == "/app.js" {
+		t.Fatalf("Fingerprint in development mode returned the name unchanged")
+	}
+	f, err := a.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil || string(data) != "console.log(1)" {
+		t.Errorf("reading %s returned %q, %v", name, data, err)
+	}
+}
+
+func writeTestFile(t *testing.T, dir string, name string, content string) {
+	err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,150 @@
+package bog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+)
+
+// FrameMagic starts every frame. Because it is kept in the data of compiled executables,
+// archived files can be found and extracted from a binary without its source.
+const FrameMagic = "\x00BOGFRM\x01"
+
+// frameFixedLen is the length of magic, header length and data length of a frame.
+const frameFixedLen = len(FrameMagic) + 4 + 8
+
+var errBadFrame = errors.New("malformed frame")
+
+// FrameHeader describes the file stored in a frame.
+type FrameHeader struct {
+	Archive string `json:"archive"`
+	// Package is the import path of the package declaring the archive, or its name if the
+	// generator could not find the import path. Archives of different packages may have the
+	// same variable name.
+	Package string      `json:"package,omitempty"`
+	Root    string      `json:"root"`
+	Path    string      `json:"path"`
+	Name    string      `json:"name"`
+	Mode    os.FileMode `json:"mode"`
+	ModTime int64       `json:"modTime"`
+	Hash    string      `json:"hash,omitempty"`
+	// ContentType is the MIME type of the data.
+	ContentType string `json:"contentType,omitempty"`
+	// Encoding is "gzip" for the frame holding the compressed variant of a file.
+	Encoding string `json:"encoding,omitempty"`
+	// Shared is set when the frame holds no data, because it is stored in the frame of
+	// another file of the archive with the same hash.
+	Shared bool `json:"shared,omitempty"`
+}
+
+// AppendFrame appends a frame holding data described by header to dst. Use internally by generator.
+//
+// A frame is made of FrameMagic, the length of the JSON encoded header as a little endian uint32,
+// the length of data as a little endian uint64, the header and the data. The frame of a file may be
+// followed by a second frame holding the gzip compressed data.
+func AppendFrame(dst []byte, header *FrameHeader, data []byte) ([]byte, error) {
+	h, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	var lengths [12]byte
+	binary.LittleEndian.PutUint32(lengths[:4], uint32(len(h)))
+	binary.LittleEndian.PutUint64(lengths[4:], uint64(len(data)))
+	dst = append(dst, FrameMagic...)
+	dst = append(dst, lengths[:]...)
+	dst = append(dst, h...)
+	return append(dst, data...), nil
+}
+
+// ReadFrame reads the frame at the beginning of b. It returns the header, the data and the length of the frame.
+func ReadFrame(b []byte) (header *FrameHeader, data []byte, n int, err error) {
+	h, data, n, err := splitFrame(b)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	header = &FrameHeader{}
+	err = json.Unmarshal(h, header)
+	if err != nil {
+		return nil, nil, 0, errBadFrame
+	}
+	return header, data, n, nil
+}
+
+func splitFrame(b []byte) (header []byte, data []byte, n int, err error) {
+	headerLen, dataLen, err := frameLengths(b, len(b))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	header = b[frameFixedLen : frameFixedLen+headerLen]
+	data = b[frameFixedLen+headerLen : frameFixedLen+headerLen+dataLen]
+	return header, data, frameFixedLen + headerLen + dataLen, nil
+}
+
+// frameLengths checks the fixed part of a frame of size bytes, which starts b.
+// It returns the lengths of the header and the data.
+func frameLengths(b []byte, size int) (headerLen int, dataLen int, err error) {
+	if len(b) < frameFixedLen || !bytes.HasPrefix(b, []byte(FrameMagic)) {
+		return 0, 0, errBadFrame
+	}
+	h := uint64(binary.LittleEndian.Uint32(b[len(FrameMagic):]))
+	d := binary.LittleEndian.Uint64(b[len(FrameMagic)+4:])
+	rest := uint64(size - frameFixedLen)
+	if h > rest || d > rest-h {
+		return 0, 0, errBadFrame
+	}
+	return int(h), int(d), nil
+}
+
+// NewFramedFile creates a File from the data held by frame. The data is not copied. Use internally by generator.
+func NewFramedFile(frame string, info os.FileInfo) File {
+	if len(frame) < frameFixedLen {
+		panic("bog: " + info.Name() + ": " + errBadFrame.Error())
+	}
+	headerLen, dataLen, err := frameLengths([]byte(frame[:frameFixedLen]), len(frame))
+	if err != nil {
+		panic("bog: " + info.Name() + ": " + err.Error())
+	}
+	n := frameFixedLen + headerLen + dataLen
+	f := &bogFile{
+		data: frame[frameFixedLen+headerLen : n],
+		stat: info,
+	}
+	f.r = strings.NewReader(f.data)
+	if n < len(frame) {
+		gzipped := frame[n:]
+		if len(gzipped) < frameFixedLen {
+			panic("bog: " + info.Name() + ": " + errBadFrame.Error())
+		}
+		headerLen, dataLen, err = frameLengths([]byte(gzipped[:frameFixedLen]), len(gzipped))
+		if err != nil {
+			panic("bog: " + info.Name() + ": " + err.Error())
+		}
+		f.gzip = gzipped[frameFixedLen+headerLen : frameFixedLen+headerLen+dataLen]
+	}
+	return f
+}
+
+// NewSharedFile creates a File holding the data of shared, an archived file with the same content.
+// frame only holds the header of the file. The data is not copied. Use internally by generator.
+func NewSharedFile(frame string, shared File, info os.FileInfo) File {
+	if len(frame) < frameFixedLen {
+		panic("bog: " + info.Name() + ": " + errBadFrame.Error())
+	}
+	if _, _, err := frameLengths([]byte(frame[:frameFixedLen]), len(frame)); err != nil {
+		panic("bog: " + info.Name() + ": " + err.Error())
+	}
+	bf, ok := shared.(*bogFile)
+	if !ok || bf.stat.IsDir() {
+		panic("bog: " + info.Name() + ": shared file is not an archived file")
+	}
+	f := &bogFile{
+		data: bf.data,
+		gzip: bf.gzip,
+		stat: info,
+	}
+	f.r = strings.NewReader(f.data)
+	return f
+}
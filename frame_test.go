@@ -0,0 +1,98 @@
+package bog
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	header := &FrameHeader{
+		Archive:     "AssetsArchive",
+		Package:     "example.com/app/assets",
+		Root:        "assets",
+		Path:        "/a.txt",
+		Name:        "a.txt",
+		Mode:        0644,
+		ModTime:     testModTime.Unix(),
+		Hash:        "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		ContentType: "text/plain; charset=utf-8",
+	}
+	b, err := AppendFrame([]byte("prefix"), header, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, data, n, err := ReadFrame(b[len("prefix"):])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, header) || string(data) != "hello" || n != len(b)-len("prefix") {
+		t.Errorf("ReadFrame = %+v, %q, %d, expected %+v, %q, %d", read, data, n, header, "hello", len(b)-len("prefix"))
+	}
+}
+
+func TestReadFrameMalformed(t *testing.T) {
+	b, err := AppendFrame(nil, &FrameHeader{Path: "/a.txt"}, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, frame := range map[string][]byte{
+		"empty":      nil,
+		"bad magic":  append([]byte("\x00BOGFRM\x02"), b[len(FrameMagic):]...),
+		"truncated":  b[:len(b)-1],
+		"short":      b[:frameFixedLen-1],
+		"bad header": []byte(FrameMagic + "\x05\x00\x00\x00" + "\x00\x00\x00\x00\x00\x00\x00\x00" + "{bad}"),
+	} {
+		_, _, _, err := ReadFrame(frame)
+		if err != errBadFrame {
+			t.Errorf("%s: ReadFrame returned %v, expected %v", name, err, errBadFrame)
+		}
+	}
+}
+
+func TestNewFramedFile(t *testing.T) {
+	info := &FileInfo{FileName: "a.txt", FileSize: 5, FileMode: 0644, FileModTime: testModTime}
+	frame, err := AppendFrame(nil, &FrameHeader{Path: "/a.txt", Name: "a.txt"}, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err = AppendFrame(frame, &FrameHeader{Path: "/a.txt", Name: "a.txt", Encoding: "gzip"}, []byte("gzipped"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewFramedFile(string(frame), info)
+	a := NewArchive(map[string]File{"/": f}, false, true, "a.txt")
+	data, err := ioutil.ReadAll(f)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("framed file holds %q, %v, expected %q", data, err, "hello")
+	}
+	if gzipped, ok := a.gzipped("a.txt"); !ok || gzipped != "gzipped" {
+		t.Errorf("framed file has gzip variant %q, %v, expected %q", gzipped, ok, "gzipped")
+	}
+
+	headerOnly, err := AppendFrame(nil, &FrameHeader{Path: "/b.txt", Name: "b.txt", Shared: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := NewSharedFile(string(headerOnly), f, &FileInfo{FileName: "b.txt", FileSize: 5, FileMode: 0600})
+	data, err = ioutil.ReadAll(shared)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("shared file holds %q, %v, expected %q", data, err, "hello")
+	}
+	if fi, _ := shared.Stat(); fi.Name() != "b.txt" || fi.Mode() != 0600 {
+		t.Errorf("shared file has info %v, expected its own", fi)
+	}
+}
+
+func TestNewFramedFileMalformed(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewFramedFile did not panic on a truncated frame")
+		}
+	}()
+	frame, err := AppendFrame(nil, &FrameHeader{Path: "/a.txt"}, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewFramedFile(string(frame[:len(frame)-1]), &FileInfo{FileName: "a.txt"})
+}
@@ -0,0 +1,326 @@
+package bog
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+)
+
+// FromFS creates an Archive holding a snapshot of the files of fsys, which are all read in memory.
+// Later changes of fsys are not seen: Stat and ReadDir describe the files as they were, and Extract
+// writes their snapshot to current folder.
+func FromFS(fsys fs.FS) (*Archive, error) {
+	files, err := snapshotFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return NewArchive(files, false, false, "."), nil
+}
+
+// FromDir creates an Archive holding a snapshot of the files of dir, like FromFS. As for generated
+// archives, Extract writes the files to a folder of current folder named after dir.
+func FromDir(dir string) (*Archive, error) {
+	files, err := snapshotFiles(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+	return NewArchive(files, false, false, dir), nil
+}
+
+// WrapFS creates an Archive reading fsys on every call, without reading anything beforehand. Stat and
+// ReadDir describe the current files of fsys, Open reads them when the returned file is read, and Extract
+// walks fsys and writes its files to current folder. Verify always succeeds, since there is no hash to
+// compare with, and Hash computes the hashes from the content.
+func WrapFS(fsys fs.FS) *Archive {
+	return &Archive{
+		files: make(map[string]File),
+		fsys:  fsys,
+		root:  ".",
+	}
+}
+
+// fsFile is a file of an fs.FS. It is opened on first use, so that creating an archive
+// from a file system does not read the files. Files which cannot seek are read in memory
+// on the first call to Seek or ReadAt.
+type fsFile struct {
+	fsys fs.FS
+	name string
+	info os.FileInfo
+	f    fs.File
+	off  int64
+	r    *bytes.Reader
+}
+
+// NewFSArchive creates an Archive holding the files of fsys found under dir, or the single file dir
+// if isFile is set. The files and folders are described by infos, keyed by archive path, so that their
+// modes, modification times, hashes and content types are the recorded ones rather than those of fsys.
+// Files are read from fsys when opened. In development mode, fsys and infos are not used.
+// Use internally by generator.
+func NewFSArchive(fsys fs.FS, dir string, infos map[string]*FileInfo, dev bool, isFile bool, root string) *Archive {
+	files := make(map[string]File)
+	if !dev {
+		files = infoFiles(fsys, dir, infos)
+	}
+	return NewArchive(files, dev, isFile, root)
+}
+
+// infoFiles returns the files of fsys described by infos, and their folders, keyed by archive path.
+// Children of folders are sorted by name.
+func infoFiles(fsys fs.FS, dir string, infos map[string]*FileInfo) map[string]File {
+	children := make(map[string][]string)
+	for name := range infos {
+		if name != "/" {
+			children[path.Dir(name)] = append(children[path.Dir(name)], name)
+		}
+	}
+	files := make(map[string]File)
+	var add func(name string) File
+	add = func(name string) File {
+		info := infos[name]
+		if !info.IsDir() {
+			files[name] = &fsFile{fsys: fsys, name: path.Join(dir, name[1:]), info: info}
+			return files[name]
+		}
+		sort.Strings(children[name])
+		folderChildren := []File{}
+		for _, child := range children[name] {
+			folderChildren = append(folderChildren, add(child))
+		}
+		files[name] = NewBogFolder(folderChildren, info)
+		return files[name]
+	}
+	if _, ok := infos["/"]; ok {
+		add("/")
+	}
+	return files
+}
+
+// snapshotFiles returns the files and folders of fsys keyed by archive path, with the content of the
+// files read in memory.
+func snapshotFiles(fsys fs.FS) (map[string]File, error) {
+	files := make(map[string]File)
+	var add func(name string, archivePath string) (File, error)
+	add = func(name string, archivePath string) (File, error) {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			files[archivePath] = NewBogFile(data, &FileInfo{
+				FileName:    info.Name(),
+				FileSize:    int64(len(data)),
+				FileMode:    info.Mode(),
+				FileModTime: info.ModTime(),
+			})
+			return files[archivePath], nil
+		}
+		entries, err := fs.ReadDir(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		children := []File{}
+		for _, entry := range entries {
+			child, err := add(path.Join(name, entry.Name()), path.Join(archivePath, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		}
+		files[archivePath] = NewBogFolder(children, &FileInfo{
+			FileName:    info.Name(),
+			FileMode:    info.Mode(),
+			FileModTime: info.ModTime(),
+		})
+		return files[archivePath], nil
+	}
+	_, err := add(".", "/")
+	return files, err
+}
+
+// fsName returns the name of fsys matching the archive path name.
+func fsName(name string) string {
+	name = path.Clean("/" + name)[1:]
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// openFS opens the named file of fsys. Errors are returned by Open, rather than by the first read.
+func openFS(fsys fs.FS, name string) (File, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fsFile{fsys: fsys, name: name, info: info, f: f}, nil
+}
+
+// readDirFS returns the file infos of the named folder of fsys.
+func readDirFS(fsys fs.FS, name string) ([]os.FileInfo, error) {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return entryInfos(entries)
+}
+
+func entryInfos(entries []fs.DirEntry) ([]os.FileInfo, error) {
+	infos := []os.FileInfo{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// filesFS returns the files and folders of the wrapped file system, keyed by archive path, as they are
+// at the time of the call.
+func (a *Archive) filesFS() (map[string]File, error) {
+	files := make(map[string]File)
+	err := a.Walk("", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		files[name] = &fsFile{fsys: a.fsys, name: fsName(name), info: info}
+		return nil
+	})
+	return files, err
+}
+
+func (f *fsFile) open() (fs.File, error) {
+	if f.f == nil {
+		file, err := f.fsys.Open(f.name)
+		if err != nil {
+			return nil, err
+		}
+		f.f = file
+	}
+	return f.f, nil
+}
+
+// buffer reads the file in memory, keeping the current offset.
+func (f *fsFile) buffer() error {
+	if f.r != nil {
+		return nil
+	}
+	data, err := fs.ReadFile(f.fsys, f.name)
+	if err != nil {
+		return err
+	}
+	f.r = bytes.NewReader(data)
+	_, err = f.r.Seek(f.off, io.SeekStart)
+	return err
+}
+
+func (f *fsFile) Close() error {
+	f.off, f.r = 0, nil
+	if f.f == nil {
+		return nil
+	}
+	err := f.f.Close()
+	f.f = nil
+	return err
+}
+
+func (f *fsFile) Name() string {
+	return f.info.Name()
+}
+
+func (f *fsFile) Read(b []byte) (int, error) {
+	if f.r != nil {
+		return f.r.Read(b)
+	}
+	file, err := f.open()
+	if err != nil {
+		return 0, err
+	}
+	n, err := file.Read(b)
+	f.off += int64(n)
+	return n, err
+}
+
+func (f *fsFile) ReadAt(b []byte, off int64) (int, error) {
+	file, err := f.open()
+	if err != nil {
+		return 0, err
+	}
+	if r, ok := file.(io.ReaderAt); ok && f.r == nil {
+		return r.ReadAt(b, off)
+	}
+	err = f.buffer()
+	if err != nil {
+		return 0, err
+	}
+	return f.r.ReadAt(b, off)
+}
+
+func (f *fsFile) Readdir(n int) ([]os.FileInfo, error) {
+	if !f.info.IsDir() {
+		return nil, &os.PathError{Op: "readdirent", Path: f.name, Err: errInvalid}
+	}
+	file, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := file.(fs.ReadDirFile)
+	if !ok {
+		return nil, &os.PathError{Op: "readdirent", Path: f.name, Err: errInvalid}
+	}
+	entries, err := d.ReadDir(n)
+	if err != nil {
+		return nil, err
+	}
+	return entryInfos(entries)
+}
+
+func (f *fsFile) Readdirnames(n int) (names []string, err error) {
+	children, err := f.Readdir(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		names = append(names, child.Name())
+	}
+	return names, nil
+}
+
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	file, err := f.open()
+	if err != nil {
+		return 0, err
+	}
+	if s, ok := file.(io.Seeker); ok && f.r == nil {
+		ret, err := s.Seek(offset, whence)
+		if err == nil {
+			f.off = ret
+		}
+		return ret, err
+	}
+	if f.info.IsDir() {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: errIsDirectory}
+	}
+	err = f.buffer()
+	if err != nil {
+		return 0, err
+	}
+	return f.r.Seek(offset, whence)
+}
+
+func (f *fsFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
@@ -0,0 +1,190 @@
+package bog
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// testFS returns a file system holding a.txt, an empty folder and sub/b.txt.
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"a.txt":     {Data: []byte("hello"), Mode: 0644, ModTime: testModTime},
+		"empty":     {Mode: os.ModeDir | 0755, ModTime: testModTime},
+		"sub/b.txt": {Data: []byte("world!"), Mode: 0600, ModTime: testModTime},
+	}
+}
+
+// walkedFiles returns the paths walked in a, and the content of its files.
+func walkedFiles(t *testing.T, a *Archive) ([]string, map[string]string) {
+	paths := []string{}
+	contents := make(map[string]string)
+	err := a.Walk("/", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		paths = append(paths, name)
+		if !info.IsDir() {
+			data, err := a.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			contents[name] = string(data)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths, contents
+}
+
+var (
+	expectedPaths    = []string{"/", "/a.txt", "/empty", "/sub", "/sub/b.txt"}
+	expectedContents = map[string]string{"/a.txt": "hello", "/sub/b.txt": "world!"}
+)
+
+func TestFromFS(t *testing.T) {
+	fsys := testFS()
+	a, err := FromFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The archive is a snapshot.
+	fsys["a.txt"].Data = []byte("changed")
+	fsys["c.txt"] = &fstest.MapFile{Data: []byte("added")}
+	paths, contents := walkedFiles(t, a)
+	if !reflect.DeepEqual(paths, expectedPaths) || !reflect.DeepEqual(contents, expectedContents) {
+		t.Errorf("walked %v %v, expected %v %v", paths, contents, expectedPaths, expectedContents)
+	}
+	info, err := a.Stat("/sub/b.txt")
+	if err != nil || info.Mode() != 0600 || !info.ModTime().Equal(testModTime) {
+		t.Errorf("Stat = %v, %v, expected the mode and modification time of the file system", info, err)
+	}
+	hash, err := a.Hash("/a.txt")
+	if err != nil || hash != sha256Hex("hello") {
+		t.Errorf("Hash = %q, %v", hash, err)
+	}
+}
+
+func TestWrapFS(t *testing.T) {
+	fsys := testFS()
+	a := WrapFS(fsys)
+	paths, contents := walkedFiles(t, a)
+	if !reflect.DeepEqual(paths, expectedPaths) || !reflect.DeepEqual(contents, expectedContents) {
+		t.Errorf("walked %v %v, expected %v %v", paths, contents, expectedPaths, expectedContents)
+	}
+	// Changes of the file system are seen.
+	fsys["a.txt"] = &fstest.MapFile{Data: []byte("changed"), Mode: 0644}
+	data, err := a.ReadFile("/a.txt")
+	if err != nil || string(data) != "changed" {
+		t.Errorf("ReadFile after a change = %q, %v", data, err)
+	}
+
+	f, err := a.Open("/sub/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b := make([]byte, 3)
+	if _, err = f.ReadAt(b, 3); err != nil || string(b) != "ld!" {
+		t.Errorf("ReadAt = %q, %v", b, err)
+	}
+	if _, err = f.Seek(1, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	rest, err := ioutil.ReadAll(f)
+	if err != nil || string(rest) != "orld!" {
+		t.Errorf("read after Seek = %q, %v", rest, err)
+	}
+	if _, err = a.Open("/missing"); err == nil {
+		t.Errorf("Open of a missing file succeeded")
+	}
+	if err = a.Verify(); err != nil {
+		t.Errorf("Verify returned %v", err)
+	}
+}
+
+// chdir changes current folder to dir for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestFromDirExtract(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "assets")
+	for name, content := range map[string]string{"a.txt": "hello", "sub/b.txt": "world!"} {
+		err := os.MkdirAll(filepath.Join(source, filepath.Dir(name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, source, name, content)
+	}
+	a, err := FromDir(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "output")
+	err = os.Mkdir(output, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, output)
+	err = a.Extract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Files are extracted to a folder named after the source folder.
+	data, err := ioutil.ReadFile(filepath.Join(output, "assets", "sub", "b.txt"))
+	if err != nil || string(data) != "world!" {
+		t.Errorf("extracted sub/b.txt holds %q, %v", data, err)
+	}
+}
+
+func TestNewFSArchive(t *testing.T) {
+	recorded := testModTime.AddDate(1, 0, 0)
+	infos := map[string]*FileInfo{
+		"/":          {FileName: "assets", FileMode: os.ModeDir | 0755, FileModTime: recorded},
+		"/a.txt":     {FileName: "a.txt", FileSize: 5, FileMode: 0640, FileModTime: recorded, FileHash: sha256Hex("hello"), FileContentType: "text/x-test"},
+		"/empty":     {FileName: "empty", FileMode: os.ModeDir | 0755, FileModTime: recorded},
+		"/sub":       {FileName: "sub", FileMode: os.ModeDir | 0755, FileModTime: recorded},
+		"/sub/b.txt": {FileName: "b.txt", FileSize: 6, FileMode: 0600, FileModTime: recorded, FileHash: sha256Hex("world!")},
+	}
+	fsys := fstest.MapFS{}
+	for name, file := range testFS() {
+		fsys["assets/"+name] = file
+	}
+	a := NewFSArchive(fsys, "assets", infos, false, false, "assets")
+	paths, contents := walkedFiles(t, a)
+	if !reflect.DeepEqual(paths, expectedPaths) || !reflect.DeepEqual(contents, expectedContents) {
+		t.Errorf("walked %v %v, expected %v %v", paths, contents, expectedPaths, expectedContents)
+	}
+	// Infos are the recorded ones, not the ones of the file system.
+	info, err := a.Stat("/a.txt")
+	if err != nil || info.Mode() != 0640 || !info.ModTime().Equal(recorded) {
+		t.Errorf("Stat = %v, %v, expected the recorded info", info, err)
+	}
+	ctype, err := a.ContentType("/a.txt")
+	if err != nil || ctype != "text/x-test" {
+		t.Errorf("ContentType = %q, %v, expected the recorded one", ctype, err)
+	}
+	if err = a.Verify(); err != nil {
+		t.Errorf("Verify returned %v", err)
+	}
+	fsys["assets/a.txt"] = &fstest.MapFile{Data: []byte("jello")}
+	if _, ok := a.Verify().(*IntegrityError); !ok {
+		t.Errorf("Verify of a changed file did not return an integrity error")
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/keimoon/bog
+
+go 1.16
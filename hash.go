@@ -0,0 +1,91 @@
+package bog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// IntegrityError is returned by Verify when the content of archived files does not match their hashes.
+type IntegrityError struct {
+	Paths []string
+}
+
+func (e *IntegrityError) Error() string {
+	return "hash mismatch: " + strings.Join(e.Paths, ", ")
+}
+
+// Hash returns the hex encoded SHA-256 hash of the named file, computed when the archive was generated.
+// In development mode, the hash is computed from the real file.
+func (a *Archive) Hash(name string) (string, error) {
+	if a.dev {
+		f, err := a.Open(name)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		return hashReader(f)
+	}
+	fi, err := a.Stat(name)
+	if err != nil {
+		return "", err
+	}
+	if fi.IsDir() {
+		return "", &os.PathError{Op: "hash", Path: name, Err: errIsDirectory}
+	}
+	if info, ok := fi.(*FileInfo); ok && info.FileHash != "" {
+		return info.FileHash, nil
+	}
+	f, err := a.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return hashReader(f)
+}
+
+// Verify computes the hashes of archived files again, and compares them with the hashes computed when
+// the archive was generated. If some files do not match, the error is of type *IntegrityError.
+// Verify always succeeds in development mode, and for archives created with FromFS, FromDir or WrapFS.
+func (a *Archive) Verify() error {
+	paths := []string{}
+	for path := range a.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	mismatches := []string{}
+	for _, path := range paths {
+		f := reopen(a.files[path])
+		fi, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		info, ok := fi.(*FileInfo)
+		if !ok || fi.IsDir() || info.FileHash == "" {
+			continue
+		}
+		hash, err := hashReader(f)
+		if err != nil {
+			return err
+		}
+		if hash != info.FileHash {
+			mismatches = append(mismatches, path)
+		}
+	}
+	if len(mismatches) > 0 {
+		return &IntegrityError{mismatches}
+	}
+	return nil
+}
+
+func hashReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	_, err := io.Copy(h, r)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
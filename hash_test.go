@@ -0,0 +1,83 @@
+package bog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func sha256Hex(s string) string {
+	hash := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(hash[:])
+}
+
+// hashedArchive returns an archive of a.txt and b.txt whose recorded hashes are the ones of hashed.
+func hashedArchive(hashed map[string]string) *Archive {
+	files := map[string]File{}
+	children := []File{}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		data := name + " content"
+		f := NewBogFile([]byte(data), &FileInfo{
+			FileName: name,
+			FileSize: int64(len(data)),
+			FileMode: 0644,
+			FileHash: sha256Hex(hashed[name]),
+		})
+		files["/"+name] = f
+		children = append(children, f)
+	}
+	files["/"] = NewBogFolder(children, &FileInfo{FileName: "assets", FileMode: os.ModeDir | 0755})
+	return NewArchive(files, false, false, "assets")
+}
+
+func TestHash(t *testing.T) {
+	a := hashedArchive(map[string]string{"a.txt": "a.txt content", "b.txt": "b.txt content"})
+	hash, err := a.Hash("/a.txt")
+	if err != nil || hash != sha256Hex("a.txt content") {
+		t.Errorf("Hash = %q, %v, expected %q", hash, err, sha256Hex("a.txt content"))
+	}
+	if _, err = a.Hash("/"); err == nil {
+		t.Errorf("Hash of a folder succeeded")
+	}
+	if _, err = a.Hash("/missing"); err == nil {
+		t.Errorf("Hash of a missing file succeeded")
+	}
+
+	// Files without recorded hash are hashed when asked.
+	hash, err = testArchive().Hash("/sub/b.txt")
+	if err != nil || hash != sha256Hex("world!") {
+		t.Errorf("Hash without recorded hash = %q, %v, expected %q", hash, err, sha256Hex("world!"))
+	}
+}
+
+func TestHashDevelopmentMode(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("on disk"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := NewArchive(nil, true, false, dir).Hash("/a.txt")
+	if err != nil || hash != sha256Hex("on disk") {
+		t.Errorf("Hash in development mode = %q, %v, expected %q", hash, err, sha256Hex("on disk"))
+	}
+}
+
+func TestVerify(t *testing.T) {
+	a := hashedArchive(map[string]string{"a.txt": "a.txt content", "b.txt": "b.txt content"})
+	if err := a.Verify(); err != nil {
+		t.Errorf("Verify of an intact archive returned %v", err)
+	}
+	a = hashedArchive(map[string]string{"a.txt": "a.txt content", "b.txt": "tampered"})
+	err := a.Verify()
+	integrityErr, ok := err.(*IntegrityError)
+	if !ok || !reflect.DeepEqual(integrityErr.Paths, []string{"/b.txt"}) {
+		t.Errorf("Verify of a tampered archive returned %v, expected an integrity error for /b.txt", err)
+	}
+	if err := testArchive().Verify(); err != nil {
+		t.Errorf("Verify of an archive without hashes returned %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package bog
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Server serves the files of an archive over HTTP. Folders are served by their index.html file.
+// Fingerprinted names, as returned by Archive.Fingerprint, are served with immutable Cache-Control
+// headers. Content hashes are sent as ETag. Files archived with a gzip compressed variant are sent
+// compressed to clients accepting gzip content encoding.
+type Server struct {
+	// Fallback is the file served with status 200 for missing paths without extension, instead of
+	// a 404 error. Single page applications typically use "/index.html".
+	Fallback string
+	// NotFound is the file served with status 404 for missing files.
+	NotFound string
+	// ListDirs lists the content of folders without index.html file.
+	ListDirs bool
+	// HideDotfiles makes files and folders whose name starts with a dot unreachable.
+	HideDotfiles bool
+
+	archive *Archive
+}
+
+// NewServer creates a Server for the archive a.
+func NewServer(a *Archive) *Server {
+	return &Server{archive: a}
+}
+
+// ServeHTTP implements http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "405 method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := path.Clean("/" + r.URL.Path)
+	fingerprinted := false
+	if logical, ok := s.archive.unfingerprint(s.archive.formatName(name)); ok {
+		name = "/" + logical
+		fingerprinted = true
+	}
+	fi, err := s.stat(name)
+	if err != nil {
+		if s.Fallback != "" && path.Ext(name) == "" {
+			fi, err = s.stat(s.Fallback)
+			if err == nil && !fi.IsDir() {
+				s.serveFile(w, r, s.Fallback, fi, false)
+				return
+			}
+		}
+		s.notFound(w, r)
+		return
+	}
+	if fi.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			http.Redirect(w, r, path.Base(r.URL.Path)+"/", http.StatusMovedPermanently)
+			return
+		}
+		index := path.Join(name, "index.html")
+		indexInfo, err := s.stat(index)
+		if err == nil && !indexInfo.IsDir() {
+			s.serveFile(w, r, index, indexInfo, false)
+			return
+		}
+		if s.ListDirs {
+			s.listDir(w, r, name)
+			return
+		}
+		s.notFound(w, r)
+		return
+	}
+	s.serveFile(w, r, name, fi, fingerprinted)
+}
+
+// stat returns the FileInfo of the named file, hiding dotfiles if requested.
+func (s *Server) stat(name string) (os.FileInfo, error) {
+	if s.HideDotfiles && isDotfile(name) {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: errNotFound}
+	}
+	return s.archive.Stat(name)
+}
+
+func isDotfile(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// notFound serves the NotFound file with status 404, or a plain text error.
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request) {
+	if s.NotFound == "" {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := s.archive.Open(s.NotFound)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	ctype, err := contentType(fi, f)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Length", strconv.FormatInt(fi.Size(), 10))
+	w.WriteHeader(http.StatusNotFound)
+	if r.Method != "HEAD" {
+		io.Copy(w, f)
+	}
+}
+
+// listDir writes an HTML list of the files of the named folder.
+func (s *Server) listDir(w http.ResponseWriter, r *http.Request, name string) {
+	infos, err := s.archive.ReadDir(name)
+	if err != nil {
+		http.Error(w, "500 internal server error", http.StatusInternalServerError)
+		return
+	}
+	sort.Sort(byName(infos))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<pre>\n")
+	for _, info := range infos {
+		childName := info.Name()
+		if s.HideDotfiles && strings.HasPrefix(childName, ".") {
+			continue
+		}
+		if info.IsDir() {
+			childName += "/"
+		}
+		link := url.URL{Path: childName}
+		fmt.Fprintf(w, "<a href=\"%s\">%s</a>\n", link.String(), html.EscapeString(childName))
+	}
+	fmt.Fprintf(w, "</pre>\n")
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, name string, fi os.FileInfo, fingerprinted bool) {
+	f, err := s.archive.Open(name)
+	if err != nil {
+		http.Error(w, "500 internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	hash, _ := s.archive.Hash(name)
+	if fingerprinted {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	ctype, err := contentType(fi, f)
+	if err != nil {
+		http.Error(w, "500 internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ctype)
+	if gzipped, ok := s.archive.gzipped(name); ok {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if acceptsGzip(r) {
+			w.Header().Set("Content-Encoding", "gzip")
+			if hash != "" {
+				w.Header().Set("Etag", `"`+hash+`-gzip"`)
+			}
+			http.ServeContent(w, r, fi.Name(), fi.ModTime(), strings.NewReader(gzipped))
+			return
+		}
+	}
+	if hash != "" {
+		w.Header().Set("Etag", `"`+hash+`"`)
+	}
+	http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
+}
+
+// ContentType returns the MIME type of the named file recorded when the archive was generated. Otherwise,
+// for example in development mode, it is guessed as Server does, from the extension of the file name or
+// from the first bytes of the file.
+func (a *Archive) ContentType(name string) (string, error) {
+	f, err := a.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if fi.IsDir() {
+		return "", &os.PathError{Op: "contenttype", Path: name, Err: errIsDirectory}
+	}
+	return contentType(fi, f)
+}
+
+// contentType returns the content type of f recorded at generation time. Otherwise, it is guessed
+// from the extension of the file name, or from the first bytes of f.
+func contentType(fi os.FileInfo, f File) (string, error) {
+	if info, ok := fi.(*FileInfo); ok && info.FileContentType != "" {
+		return info.FileContentType, nil
+	}
+	ctype := mime.TypeByExtension(path.Ext(fi.Name()))
+	if ctype != "" {
+		return ctype, nil
+	}
+	var buf [512]byte
+	n, err := io.ReadFull(f, buf[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of r allows gzip content encoding.
+func acceptsGzip(r *http.Request) bool {
+	accepted := false
+	for _, header := range r.Header["Accept-Encoding"] {
+		for _, coding := range strings.Split(header, ",") {
+			params := strings.Split(coding, ";")
+			name := strings.ToLower(strings.TrimSpace(params[0]))
+			if name != "gzip" && name != "*" {
+				continue
+			}
+			q := 1.0
+			for _, param := range params[1:] {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(param, "q=") {
+					if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+						q = v
+					}
+				}
+			}
+			if name == "gzip" {
+				return q > 0
+			}
+			accepted = q > 0
+		}
+	}
+	return accepted
+}
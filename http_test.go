@@ -0,0 +1,234 @@
+package bog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// siteFiles are the files of siteArchive, by path.
+var siteFiles = map[string]string{
+	"/index.html":     "<h1>home</h1>",
+	"/app.js":         "console.log(1)",
+	"/.env":           "SECRET=1",
+	"/docs/page.html": "<h1>page</h1>",
+	"/404.html":       "<h1>not found</h1>",
+}
+
+// siteArchive returns an archive of siteFiles and of the gzipped files, whose frames hold the gzip
+// variant given by gzipped.
+func siteArchive(t *testing.T, gzipped map[string]string) *Archive {
+	files := map[string]File{}
+	children := map[string][]File{}
+	for name, content := range siteFiles {
+		base := name[strings.LastIndex(name, "/")+1:]
+		info := &FileInfo{FileName: base, FileSize: int64(len(content)), FileMode: 0644, FileModTime: testModTime}
+		var f File
+		if variant, ok := gzipped[name]; ok {
+			frame, err := AppendFrame(nil, &FrameHeader{Path: name, Name: base}, []byte(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			frame, err = AppendFrame(frame, &FrameHeader{Path: name, Name: base, Encoding: "gzip"}, []byte(variant))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f = NewFramedFile(string(frame), info)
+		} else {
+			f = NewBogFile([]byte(content), info)
+		}
+		files[name] = f
+		dir := name[:strings.LastIndex(name, "/")]
+		children[dir] = append(children[dir], f)
+	}
+	docs := NewBogFolder(children["/docs"], &FileInfo{FileName: "docs", FileMode: os.ModeDir | 0755, FileModTime: testModTime})
+	files["/docs"] = docs
+	files["/"] = NewBogFolder(append(children[""], docs), &FileInfo{FileName: "site", FileMode: os.ModeDir | 0755, FileModTime: testModTime})
+	return NewArchive(files, false, false, "site")
+}
+
+// get serves a request for target, with the given request headers.
+func get(s http.Handler, method string, target string, headers ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestServer(t *testing.T) {
+	a := siteArchive(t, nil)
+	s := NewServer(a)
+	tests := []struct {
+		method string
+		target string
+		status int
+		body   string
+	}{
+		{"GET", "/app.js", 200, "console.log(1)"},
+		{"HEAD", "/app.js", 200, ""},
+		{"GET", "/", 200, "<h1>home</h1>"},
+		{"GET", "/docs/page.html", 200, "<h1>page</h1>"},
+		{"GET", "/docs", 301, ""},
+		{"GET", "/docs/", 404, "404 page not found\n"},
+		{"GET", "/missing.js", 404, "404 page not found\n"},
+		{"POST", "/app.js", 405, "405 method not allowed\n"},
+	}
+	for _, test := range tests {
+		w := get(s, test.method, test.target)
+		if w.Code != test.status || (test.body != "" || test.method == "HEAD") && w.Body.String() != test.body {
+			t.Errorf("%s %s: %d %q, expected %d %q", test.method, test.target, w.Code, w.Body.String(), test.status, test.body)
+		}
+	}
+
+	w := get(s, "GET", "/app.js")
+	hash := sha256Hex(siteFiles["/app.js"])
+	if etag := w.Header().Get("Etag"); etag != `"`+hash+`"` {
+		t.Errorf("ETag %q, expected the content hash", etag)
+	}
+	if w.Header().Get("Cache-Control") != "" {
+		t.Errorf("logical name served with Cache-Control %q", w.Header().Get("Cache-Control"))
+	}
+	if w = get(s, "GET", "/app.js", "If-None-Match", `"`+hash+`"`); w.Code != http.StatusNotModified {
+		t.Errorf("conditional request returned %d, expected %d", w.Code, http.StatusNotModified)
+	}
+}
+
+func TestServerFingerprinted(t *testing.T) {
+	a := siteArchive(t, nil)
+	s := NewServer(a)
+	name := a.Fingerprint("/app.js")
+	w := get(s, "GET", name)
+	if w.Code != 200 || w.Body.String() != siteFiles["/app.js"] {
+		t.Fatalf("GET %s: %d %q", name, w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=31536000, immutable" {
+		t.Errorf("fingerprinted name served with Cache-Control %q", cc)
+	}
+	if ctype := w.Header().Get("Content-Type"); !strings.HasPrefix(ctype, "text/javascript") {
+		t.Errorf("fingerprinted name served with Content-Type %q", ctype)
+	}
+}
+
+func TestServerGzip(t *testing.T) {
+	s := NewServer(siteArchive(t, map[string]string{"/app.js": "gzipped app.js"}))
+	hash := sha256Hex(siteFiles["/app.js"])
+	w := get(s, "GET", "/app.js", "Accept-Encoding", "br, gzip")
+	if w.Body.String() != "gzipped app.js" || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("client accepting gzip got %q with Content-Encoding %q", w.Body.String(), w.Header().Get("Content-Encoding"))
+	}
+	if etag := w.Header().Get("Etag"); etag != `"`+hash+`-gzip"` {
+		t.Errorf("gzip variant has ETag %q, expected a different ETag than the plain content", etag)
+	}
+	if ctype := w.Header().Get("Content-Type"); !strings.HasPrefix(ctype, "text/javascript") {
+		t.Errorf("gzip variant served with Content-Type %q", ctype)
+	}
+	for _, accept := range []string{"", "gzip;q=0", "identity"} {
+		w = get(s, "GET", "/app.js", "Accept-Encoding", accept)
+		if w.Body.String() != siteFiles["/app.js"] || w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("client accepting %q got %q with Content-Encoding %q", accept, w.Body.String(), w.Header().Get("Content-Encoding"))
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("client accepting %q got Vary %q", accept, w.Header().Get("Vary"))
+		}
+	}
+	// Files without gzip variant are sent as they are.
+	w = get(s, "GET", "/index.html", "Accept-Encoding", "gzip")
+	if w.Header().Get("Content-Encoding") != "" || w.Header().Get("Vary") != "" {
+		t.Errorf("file without gzip variant got Content-Encoding %q and Vary %q", w.Header().Get("Content-Encoding"), w.Header().Get("Vary"))
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                   false,
+		"gzip":               true,
+		"deflate, gzip":      true,
+		"GZIP;q=0.5":         true,
+		"gzip;q=0":           false,
+		"*":                  true,
+		"*;q=0":              false,
+		"gzip;q=0, *":        false,
+		"br;q=1.0, identity": false,
+		"identity, *;q=0.1":  true,
+	}
+	for header, expected := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if header != "" {
+			r.Header.Set("Accept-Encoding", header)
+		}
+		if got := acceptsGzip(r); got != expected {
+			t.Errorf("acceptsGzip(%q) = %v, expected %v", header, got, expected)
+		}
+	}
+}
+
+func TestServerFallback(t *testing.T) {
+	s := NewServer(siteArchive(t, nil))
+	s.Fallback = "/index.html"
+	s.NotFound = "/404.html"
+	tests := []struct {
+		target string
+		status int
+		body   string
+	}{
+		// Paths without extension are routes of the application.
+		{"/users/42", 200, "<h1>home</h1>"},
+		{"/docs/page.html", 200, "<h1>page</h1>"},
+		// Missing files are not.
+		{"/missing.js", 404, "<h1>not found</h1>"},
+	}
+	for _, test := range tests {
+		w := get(s, "GET", test.target)
+		if w.Code != test.status || w.Body.String() != test.body {
+			t.Errorf("GET %s: %d %q, expected %d %q", test.target, w.Code, w.Body.String(), test.status, test.body)
+		}
+	}
+	w := get(s, "HEAD", "/missing.js")
+	if w.Code != 404 || w.Body.Len() != 0 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("HEAD /missing.js: %d %q with Content-Type %q", w.Code, w.Body.String(), w.Header().Get("Content-Type"))
+	}
+
+	// A missing fallback file gives a 404 error.
+	s.Fallback = "/missing.html"
+	if w = get(s, "GET", "/users/42"); w.Code != 404 || w.Body.String() != "<h1>not found</h1>" {
+		t.Errorf("GET /users/42 with a missing fallback: %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestServerListDirs(t *testing.T) {
+	s := NewServer(siteArchive(t, nil))
+	s.ListDirs = true
+	w := get(s, "GET", "/docs/")
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `<a href="page.html">page.html</a>`) {
+		t.Errorf("GET /docs/: %d %q, expected a list of the folder", w.Code, w.Body.String())
+	}
+	// Folders with an index.html file are not listed.
+	if w = get(s, "GET", "/"); w.Body.String() != "<h1>home</h1>" {
+		t.Errorf("GET /: %q, expected index.html", w.Body.String())
+	}
+}
+
+func TestServerHideDotfiles(t *testing.T) {
+	s := NewServer(siteArchive(t, nil))
+	if w := get(s, "GET", "/.env"); w.Code != 200 {
+		t.Errorf("GET /.env: %d, expected dotfiles to be served by default", w.Code)
+	}
+	s.HideDotfiles = true
+	s.NotFound = "/404.html"
+	if w := get(s, "GET", "/.env"); w.Code != 404 || strings.Contains(w.Body.String(), "SECRET") {
+		t.Errorf("GET /.env: %d %q, expected 404 with hidden dotfiles", w.Code, w.Body.String())
+	}
+	s.ListDirs = true
+	s.archive = NewArchive(map[string]File{
+		"/":     NewBogFolder([]File{s.archive.files["/.env"], s.archive.files["/app.js"]}, &FileInfo{FileName: "site", FileMode: os.ModeDir | 0755}),
+		"/.env": s.archive.files["/.env"],
+	}, false, false, "site")
+	if w := get(s, "GET", "/"); strings.Contains(w.Body.String(), ".env") || !strings.Contains(w.Body.String(), "app.js") {
+		t.Errorf("GET /: %q, expected a list without dotfiles", w.Body.String())
+	}
+}
@@ -0,0 +1,201 @@
+package bog
+
+import (
+	"errors"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+// HTMLTemplates holds the html/template templates of an archive, parsed by ParseHTMLTemplates.
+// In development mode, the templates are parsed again when the matched files change. The files
+// are checked at most once per second.
+type HTMLTemplates struct {
+	set   templateSet
+	funcs htmltemplate.FuncMap
+	tmpl  *htmltemplate.Template
+}
+
+// ParseHTMLTemplates parses the files of the archive matched by patterns as html/template templates.
+// Patterns use the syntax of path.Match, and are matched against the path of files relative to the
+// root of the archive, such as "views/*.html". Templates are named by this path. funcs may be nil.
+func (a *Archive) ParseHTMLTemplates(funcs htmltemplate.FuncMap, patterns ...string) (*HTMLTemplates, error) {
+	t := &HTMLTemplates{
+		set:   templateSet{archive: a, patterns: patterns},
+		funcs: funcs,
+	}
+	_, err := t.Template()
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Template returns the parsed templates. In development mode, they are parsed again if the
+// matched files changed since the last call.
+func (t *HTMLTemplates) Template() (*htmltemplate.Template, error) {
+	t.set.mu.Lock()
+	defer t.set.mu.Unlock()
+	files, changed, err := t.set.changed(t.tmpl != nil)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return t.tmpl, nil
+	}
+	tmpl := htmltemplate.New("").Funcs(t.funcs)
+	for _, file := range files {
+		_, err = tmpl.New(file.name).Parse(string(file.data))
+		if err != nil {
+			return nil, err
+		}
+	}
+	t.tmpl = tmpl
+	t.set.stamp = t.set.next
+	return tmpl, nil
+}
+
+// ExecuteTemplate applies the template with the given name to data, and writes the output to w.
+func (t *HTMLTemplates) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	tmpl, err := t.Template()
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+// TextTemplates holds the text/template templates of an archive, parsed by ParseTextTemplates.
+// In development mode, the templates are parsed again when the matched files change. The files
+// are checked at most once per second.
+type TextTemplates struct {
+	set   templateSet
+	funcs texttemplate.FuncMap
+	tmpl  *texttemplate.Template
+}
+
+// ParseTextTemplates parses the files of the archive matched by patterns as text/template templates.
+// Patterns and template names are the same as for ParseHTMLTemplates. funcs may be nil.
+func (a *Archive) ParseTextTemplates(funcs texttemplate.FuncMap, patterns ...string) (*TextTemplates, error) {
+	t := &TextTemplates{
+		set:   templateSet{archive: a, patterns: patterns},
+		funcs: funcs,
+	}
+	_, err := t.Template()
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Template returns the parsed templates. In development mode, they are parsed again if the
+// matched files changed since the last call.
+func (t *TextTemplates) Template() (*texttemplate.Template, error) {
+	t.set.mu.Lock()
+	defer t.set.mu.Unlock()
+	files, changed, err := t.set.changed(t.tmpl != nil)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return t.tmpl, nil
+	}
+	tmpl := texttemplate.New("").Funcs(t.funcs)
+	for _, file := range files {
+		_, err = tmpl.New(file.name).Parse(string(file.data))
+		if err != nil {
+			return nil, err
+		}
+	}
+	t.tmpl = tmpl
+	t.set.stamp = t.set.next
+	return tmpl, nil
+}
+
+// ExecuteTemplate applies the template with the given name to data, and writes the output to w.
+func (t *TextTemplates) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	tmpl, err := t.Template()
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+// templateSet finds the files matched by template patterns, and tells when they change.
+type templateSet struct {
+	archive  *Archive
+	patterns []string
+	mu       sync.Mutex
+	// stamp describes the files of the parsed templates, next the files found by the last call to changed.
+	stamp string
+	next  string
+	// checked is the time the files were last walked.
+	checked time.Time
+}
+
+type templateFile struct {
+	name string
+	data []byte
+}
+
+// templateCheckInterval is the minimum time between two walks of the archive looking for changed
+// templates in development mode, so that every request does not stat all the archived files.
+const templateCheckInterval = time.Second
+
+var errNoTemplates = errors.New("no files matched by template patterns")
+
+// changed reports whether the templates must be parsed, and returns the matched files if so.
+// Parsed templates are only parsed again in development mode, when the matched files changed.
+func (s *templateSet) changed(parsed bool) ([]*templateFile, bool, error) {
+	if parsed && (!s.archive.dev || time.Since(s.checked) < templateCheckInterval) {
+		return nil, false, nil
+	}
+	s.checked = time.Now()
+	names := []string{}
+	var stamp strings.Builder
+	err := s.archive.Walk("", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		name = strings.TrimPrefix(name, "/")
+		for _, pattern := range s.patterns {
+			matched, err := path.Match(pattern, name)
+			if err != nil {
+				return err
+			}
+			if matched {
+				names = append(names, name)
+				stamp.WriteString(name + "\x00" + strconv.FormatInt(info.Size(), 10) + "\x00" + strconv.FormatInt(info.ModTime().UnixNano(), 10) + "\x00")
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(names) == 0 {
+		return nil, false, errNoTemplates
+	}
+	s.next = stamp.String()
+	if parsed && s.next == s.stamp {
+		return nil, false, nil
+	}
+	files := []*templateFile{}
+	for _, name := range names {
+		data, err := s.archive.ReadFile(name)
+		if err != nil {
+			return nil, false, err
+		}
+		files = append(files, &templateFile{name, data})
+	}
+	return files, true, nil
+}
@@ -0,0 +1,60 @@
+package bog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTemplatesDevelopmentMode(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "index.tmpl")
+	err := ioutil.WriteFile(filename, []byte("one"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewArchive(nil, true, false, dir)
+	views, err := a.ParseTextTemplates(nil, "*.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	execute := func() string {
+		var b bytes.Buffer
+		err := views.ExecuteTemplate(&b, "index.tmpl", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+	if got := execute(); got != "one" {
+		t.Fatalf("executed %q, expected %q", got, "one")
+	}
+
+	err = ioutil.WriteFile(filename, []byte("two"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	err = os.Chtimes(filename, later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The files were checked less than templateCheckInterval ago.
+	if got := execute(); got != "one" {
+		t.Errorf("executed %q right after parsing, expected %q", got, "one")
+	}
+	views.set.checked = time.Now().Add(-templateCheckInterval)
+	if got := execute(); got != "two" {
+		t.Errorf("executed %q after the check interval, expected %q", got, "two")
+	}
+}
+
+func TestTemplatesNoMatch(t *testing.T) {
+	_, err := testArchive().ParseHTMLTemplates(nil, "*.html")
+	if err != errNoTemplates {
+		t.Errorf("parsing templates without matched files returned %v, expected %v", err, errNoTemplates)
+	}
+}
@@ -2,115 +2,92 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/keimoon/bog"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
 	"text/template"
-	"time"
 )
 
-// Archive creates an archive from a folder or file
+// Archive creates an archive from a folder or file. Without argument, it creates the archives
+// described by the project config.
 func Archive() int {
 	if len(Args) <= 1 {
-		Usage()
-		return 2
+		if _, err := os.Stat(projectConfig); err != nil {
+			Usage()
+			return 2
+		}
+		return archiveConfig(projectConfig)
 	}
-	folder := Args[1]
-	stat, err := os.Stat(folder)
+	p := newParams(Args[1])
+	_, ch, err := generate(p)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		fmt.Println(err)
 		return 1
 	}
-	filePackageName := makePackageName(folder)
-	var outputFileName string
-	var varName string
-	if len(filePackageName) > 0 {
-		outputFileName = filePackageName + "-archive.go"
-		varName = makePublicVariableName(filePackageName) + "Archive"
-	} else {
-		outputFileName = Options.PackageName + "-archive.go"
-		varName = makePublicVariableName(Options.PackageName) + "Archive"
+	ch.print(os.Stdout, p.Output)
+	return 0
+}
+
+// archiveConfig creates every archive described by the project config filename.
+func archiveConfig(filename string) int {
+	allParams, err := loadConfig(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
 	}
-	outputFolder := "."
-	if !Options.isCwd && Options.PackageName != "main" {
-		outputFolder = Options.PackageName
+	status := 0
+	for i, p := range allParams {
+		_, ch, err := generate(p)
 		if err != nil {
-			fmt.Println(err)
-			return 1
+			fmt.Fprintf(os.Stderr, "%s: archives[%d]: %v\n", filename, i, err)
+			status = 1
+			continue
 		}
+		ch.print(os.Stdout, p.Output)
 	}
-	err = os.RemoveAll(outputFileName)
+	return status
+}
+
+// generate writes the Go source of the archive described by p, and its manifest if requested.
+// Files are read and rendered in parallel by Options.Jobs workers, and written in order, so that
+// only a few of them are held in memory.
+// With a split size, the declarations of files are written to shards next to the generated file.
+// It returns the names of the written or removed files whose content changed, and the files changed
+// since the previous generation. In dry run mode, nothing is written.
+func generate(p *params) ([]string, *changes, error) {
+	header, err := p.marshal()
 	if err != nil {
-		fmt.Println(err)
-		return 1
+		return nil, nil, err
 	}
-	fileVars := []*FileVar{}
-	now := strconv.FormatInt(time.Now().Unix(), 10)
-	if !Options.Dev {
-		if stat.IsDir() {
-			err = walk(folder, func(path string, info os.FileInfo, children []string) error {
-				fileVar := &FileVar{
-					VarName: makeVariableName(now + "_" + path),
-					Path:    "/" + strings.TrimLeft(strings.TrimPrefix(path, folder), "/"),
-					Stat: &bog.FileInfo{
-						FileName:    info.Name(),
-						FileSize:    info.Size(),
-						FileMode:    info.Mode(),
-						FileModTime: info.ModTime(),
-					},
-				}
-				if info.IsDir() {
-					fileVar.IsDir = true
-					for _, child := range children {
-						fileVar.Children = append(fileVar.Children, makeVariableName(now+"_"+path+"_"+child))
-					}
-				} else {
-					b, err := ioutil.ReadFile(path)
-					if err != nil {
-						return err
-					}
-					fileVar.Data = b
-				}
-				fileVars = append(fileVars, fileVar)
-				return nil
-			}, ignoreRules{})
-			if err != nil {
-				fmt.Println(err)
-				return 1
-			}
-		} else {
-			fileVar := &FileVar{
-				VarName: makeVariableName(now + "_" + folder),
-				Path:    "/",
-				Stat:    stat,
-			}
-			b, err := ioutil.ReadFile(folder)
-			if err != nil {
-				return 2
-			}
-			fileVar.Data = b
-			fileVars = append(fileVars, fileVar)
-		}
+	if p.Dev && p.Fingerprint {
+		return nil, nil, errors.New("cannot write a manifest in development mode")
 	}
-	if !Options.isCwd {
-		err = os.MkdirAll(outputFolder, 0755)
+	if p.Embed {
+		return generateEmbed(p, header)
 	}
-	f, err := os.Create(filepath.Join(outputFolder, outputFileName))
+	p.pkgPath = packagePath(filepath.Dir(p.Output), p.Package)
+	p.cache = openCache(p, header)
+	defer func() { p.cache = nil }()
+	fileVars, mimeTypes, isFile, err := collect(p)
 	if err != nil {
-		fmt.Println(err)
-		return 1
+		p.cache.close()
+		return nil, nil, err
 	}
-	defer f.Close()
+	defer p.sources.close()
 	mainTmpl, err := loadTemplate("main.go.tmpl")
 	if err != nil {
-		fmt.Println(err)
-		return 1
+		p.cache.close()
+		return nil, nil, err
 	}
 	tmplData := &struct {
+		Params      string
 		PackageName string
 		Files       []*FileVar
 		Root        string
@@ -118,29 +95,301 @@ func Archive() int {
 		IsFile      bool
 		Dev         bool
 	}{
-		PackageName: Options.PackageName,
+		Params:      header,
+		PackageName: p.Package,
 		Files:       fileVars,
-		Root:        folder,
-		VarName:     varName,
-		IsFile:      !stat.IsDir(),
-		Dev:         Options.Dev,
+		Root:        p.Root,
+		VarName:     p.Var,
+		IsFile:      isFile,
+		Dev:         p.Dev,
+	}
+	out := newOutputWriter(p.Output, p.dryRun)
+	w := bufio.NewWriter(out)
+	shards := &shardWriter{p: p, tmpl: mainTmpl}
+	split := p.SplitSize > 0 && !isFile
+	fail := func(err error) ([]string, *changes, error) {
+		out.abort()
+		shards.abort()
+		p.cache.close()
+		return nil, nil, err
 	}
-	err = mainTmpl.Execute(f, tmplData)
+	err = mainTmpl.ExecuteTemplate(w, "header", tmplData)
 	if err != nil {
-		fmt.Println(err)
-		return 1
+		return fail(err)
 	}
-	return 0
+	var problems validationError
+	// owners holds the variables of the files holding the data of each hash.
+	owners := make(map[string]string)
+	duplicates, saved := 0, int64(0)
+	err = renderFiles(p, mainTmpl, mimeTypes, fileVars, Options.Jobs, func(fileVar *FileVar, r *rendered) error {
+		if r.err != nil {
+			return r.err
+		}
+		problems = append(problems, r.errs...)
+		if len(problems) > 0 {
+			return nil
+		}
+		fragment := r.fragment
+		if !fileVar.IsDir && fileVar.Stat.Size() > 0 {
+			if owner, ok := owners[fileVar.Hash]; ok {
+				fileVar.Shared = owner
+				var err error
+				fragment, err = renderShared(p, mainTmpl, fileVar)
+				if err != nil {
+					return err
+				}
+				duplicates++
+				saved += fileVar.Stat.Size()
+			} else {
+				owners[fileVar.Hash] = fileVar.VarName
+			}
+		}
+		var err error
+		if split && !fileVar.IsDir {
+			err = shards.write(fragment)
+		} else {
+			_, err = w.WriteString(fragment)
+		}
+		if err != nil {
+			return err
+		}
+		// The cache keeps the declaration holding the data, because the shared file may change.
+		return p.cache.add(fileVar, r.fragment)
+	})
+	if err != nil {
+		return fail(err)
+	}
+	if len(problems) > 0 {
+		return fail(problems)
+	}
+	err = mainTmpl.ExecuteTemplate(w, "footer", tmplData)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = shards.close()
+	}
+	if err != nil {
+		return fail(err)
+	}
+	written := []string{}
+	changed, err := out.commit()
+	if err != nil {
+		p.cache.close()
+		return nil, nil, err
+	}
+	if changed {
+		written = append(written, p.Output)
+	}
+	written = append(written, shards.written...)
+	removed, err := shards.removeStale()
+	if err != nil {
+		p.cache.close()
+		return nil, nil, err
+	}
+	written = append(written, removed...)
+	if p.Fingerprint {
+		manifest, err := makeManifest(fileVars, isFile)
+		if err != nil {
+			p.cache.close()
+			return nil, nil, err
+		}
+		changed, err := writeIfChanged(p.manifestName(), manifest, p.dryRun)
+		if err != nil {
+			p.cache.close()
+			return nil, nil, err
+		}
+		if changed {
+			written = append(written, p.manifestName())
+		}
+	}
+	ch := p.cache.finish(fileVars)
+	ch.Duplicates, ch.Saved = duplicates, saved
+	return written, ch, nil
+}
+
+// collect lists the files and folders of the archive described by p, children first, without
+// reading the content of files. It also returns the MIME types of the archive, and whether the
+// source is a single file.
+func collect(p *params) ([]*FileVar, mimeTypes, bool, error) {
+	p.sources = &sourceFiles{}
+	names := newVarNames()
+	var fileVars []*FileVar
+	var config sourceConfig
+	isFile := false
+	var err error
+	if len(p.Mounts) > 0 {
+		fileVars, config, err = collectMounts(p, names)
+	} else {
+		fileVars, config, isFile, err = collectSource(p, names)
+	}
+	if err != nil {
+		p.sources.close()
+		return nil, nil, false, err
+	}
+	mimeTypes, err := newMimeTypes(p, config)
+	if err != nil {
+		p.sources.close()
+		return nil, nil, false, err
+	}
+	return fileVars, mimeTypes, isFile, nil
+}
+
+// load reads, checks and transforms a file, and computes its hash, content type and frame.
+// It returns the problems found by the checks.
+func load(p *params, mimeTypes mimeTypes, fileVar *FileVar) (validationError, error) {
+	if fileVar.IsDir {
+		return nil, nil
+	}
+	if fileVar.File != "" {
+		b, err := ioutil.ReadFile(fileVar.File)
+		if err != nil {
+			return nil, err
+		}
+		fileVar.Data = b
+		if p.cache != nil {
+			hash := sha256.Sum256(b)
+			fileVar.sourceHash = hex.EncodeToString(hash[:])
+		}
+	} else if fileVar.read != nil {
+		b, err := fileVar.read()
+		if err != nil {
+			return nil, err
+		}
+		fileVar.Data = b
+	}
+	if errs := validateFile(p, fileVar); len(errs) > 0 {
+		return errs, nil
+	}
+	err := transformFile(p, fileVar)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(fileVar.Data)
+	fileVar.Hash = hex.EncodeToString(hash[:])
+	fileVar.ContentType = mimeTypes.detect(fileVar.Stat.Name(), fileVar.Data)
+	fileVar.Frame, err = bog.AppendFrame(nil, frameHeader(p, fileVar), fileVar.Data)
+	if err != nil {
+		return nil, err
+	}
+	if p.Gzip {
+		fileVar.Frame, err = appendGzipFrame(fileVar.Frame, p, fileVar)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fileVar.Raw = p.Raw && rawText(fileVar.Data)
+	return nil, nil
+}
+
+// frameHeader returns the header of the frame of a file.
+func frameHeader(p *params, fileVar *FileVar) *bog.FrameHeader {
+	return &bog.FrameHeader{
+		Archive:     p.Var,
+		Package:     p.pkgPath,
+		Root:        p.Root,
+		Path:        fileVar.Path,
+		Name:        fileVar.Stat.Name(),
+		Mode:        fileVar.Stat.Mode(),
+		ModTime:     fileVar.Stat.ModTime().Unix(),
+		Hash:        fileVar.Hash,
+		ContentType: fileVar.ContentType,
+	}
+}
+
+// makeManifest returns the JSON encoded map from logical names to fingerprinted names of archived files.
+func makeManifest(fileVars []*FileVar, isFile bool) ([]byte, error) {
+	manifest := make(map[string]string)
+	if !isFile {
+		for _, fileVar := range fileVars {
+			if !fileVar.IsDir {
+				manifest[fileVar.Path] = bog.FingerprintName(fileVar.Path, fileVar.Hash)
+			}
+		}
+	}
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// writeIfChanged writes b to filename, creating parent folders when needed.
+// The file is left untouched if its content is already b, or in dry run mode.
+// It reports whether the content changed.
+func writeIfChanged(filename string, b []byte, dryRun bool) (bool, error) {
+	old, err := ioutil.ReadFile(filename)
+	if err == nil && bytes.Equal(old, b) {
+		return false, nil
+	}
+	if dryRun {
+		return true, nil
+	}
+	if dir := filepath.Dir(filename); dir != "." {
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, ioutil.WriteFile(filename, b, 0644)
 }
 
 // FileVar represents a file or folder
 type FileVar struct {
-	VarName  string
-	Path     string
-	IsDir    bool
-	Stat     os.FileInfo
-	Data     []byte
-	Children []string
+	VarName     string
+	Path        string
+	IsDir       bool
+	Stat        os.FileInfo
+	Data        []byte
+	Hash        string
+	ContentType string
+	Frame       []byte
+	Children    []string
+	// Origin is the name of the file in messages, when it is not found from its path.
+	Origin string
+	// Source describes the file read from a folder, and is the key of its cache entry.
+	Source os.FileInfo
+	// File is the path of the file to read, when Data is not read yet.
+	File string
+	// read reads the data of an entry of a tar or zip source, when Data is not read yet.
+	read func() ([]byte, error)
+	// Raw is set when the data is written as a raw string literal.
+	Raw bool
+	// Shared is the variable of the file holding the data, when another file has the same content.
+	Shared string
+	// Cached is set when the size, mode and modification time of the file match its cache entry. The
+	// rendered declaration comes from the cache if the file also has the recorded hash.
+	Cached *cacheEntry
+	// sourceHash is the hash of the file read from a folder, before transforms, recorded in its cache entry.
+	sourceHash string
+}
+
+// varNames hands out unique variable names for archive paths. Names only
+// depend on the archive variable and the path, so generation is reproducible.
+type varNames struct {
+	names map[string]string
+	used  map[string]bool
+}
+
+func newVarNames() *varNames {
+	return &varNames{
+		names: make(map[string]string),
+		used:  make(map[string]bool),
+	}
+}
+
+func (n *varNames) get(archiveVar string, path string) string {
+	key := archiveVar + "_" + path
+	if name, ok := n.names[key]; ok {
+		return name
+	}
+	name := makeVariableName(key)
+	for i := 2; n.used[name]; i++ {
+		name = fmt.Sprintf("%s%d", makeVariableName(key), i)
+	}
+	n.names[key] = name
+	n.used[name] = true
+	return name
 }
 
 type ignoreRules []string
@@ -159,7 +408,14 @@ func (r ignoreRules) ignore(name string) bool {
 
 type walkFunc func(path string, info os.FileInfo, children []string) error
 
-func walk(root string, walkFn walkFunc, rules ignoreRules) error {
+// walk calls walkFn for every file under root, children first. The file
+// named output, if any, is skipped so that an archive never contains itself,
+// and so are the config files of root.
+func walk(root string, walkFn walkFunc, rules ignoreRules, output string) error {
+	return walkFolder(root, walkFn, rules, output, true)
+}
+
+func walkFolder(root string, walkFn walkFunc, rules ignoreRules, output string, isRoot bool) error {
 	info, err := os.Stat(root)
 	if err != nil {
 		return err
@@ -182,10 +438,16 @@ func walk(root string, walkFn walkFunc, rules ignoreRules) error {
 			return err
 		}
 		for _, childInfo := range childrenInfos {
-			if rules.ignore(childInfo.Name()) {
+			if rules.ignore(childInfo.Name()) || isRoot && isConfigFile(childInfo.Name()) {
 				continue
 			}
-			err = walk(filepath.Join(root, childInfo.Name()), walkFn, rules)
+			childPath := filepath.Join(root, childInfo.Name())
+			if output != "" {
+				if abs, err := filepath.Abs(childPath); err == nil && abs == output {
+					continue
+				}
+			}
+			err = walkFolder(childPath, walkFn, rules, output, false)
 			if err != nil {
 				return err
 			}
@@ -196,7 +458,7 @@ func walk(root string, walkFn walkFunc, rules ignoreRules) error {
 }
 
 func loadTemplate(name string) (*template.Template, error) {
-	f, err := TemplatesArchive.Open("main.go.tmpl")
+	f, err := TemplatesArchive.Open(name)
 	if err != nil {
 		return nil, err
 	}
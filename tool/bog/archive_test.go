@@ -0,0 +1,114 @@
+package main
+
+import (
+	"archive/tar"
+	"github.com/keimoon/bog"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTar writes the files of dir to a tar file.
+func writeTar(t *testing.T, dir string, filename string) {
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	err = filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// generateAndLoad generates the archive of the files under a temporary folder with the params
+// set by configure, and loads it back from the generated file.
+func generateAndLoad(t *testing.T, files map[string]string, configure func(p *params)) (*bog.Archive, *changes, string) {
+	setenv(t, "BOGCACHE", "off")
+	dir := t.TempDir()
+	source := filepath.Join(dir, "assets")
+	writeFiles(t, source, files)
+	p := defaultParams(source, "assets", true)
+	p.Source = source
+	p.Output = filepath.Join(dir, "assets-archive.go")
+	if configure != nil {
+		configure(p)
+	}
+	_, ch, err := generate(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := ioutil.ReadFile(p.Output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := loadArchive(p.Output, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		data, err := archive.ReadFile(name)
+		if err != nil || string(data) != content {
+			t.Errorf("%s: loaded %q, %v, expected %q", name, data, err, content)
+		}
+	}
+	err = archive.Verify()
+	if err != nil {
+		t.Error(err)
+	}
+	return archive, ch, string(generated)
+}
+
+func TestGenerateDuplicates(t *testing.T) {
+	archive, ch, generated := generateAndLoad(t, map[string]string{
+		"a.txt":      "same content",
+		"copy/a.txt": "same content",
+		"copy/b.txt": "same content",
+		"other.txt":  "other content",
+		"empty.txt":  "",
+		"empty2.txt": "",
+	}, func(p *params) { p.Gzip = true })
+	if ch.Duplicates != 2 || ch.Saved != 2*int64(len("same content")) {
+		t.Errorf("%d duplicates saving %d bytes, expected 2 saving %d", ch.Duplicates, ch.Saved, 2*len("same content"))
+	}
+	if n := strings.Count(generated, "bog.NewSharedFile("); n != 2 {
+		t.Errorf("generated file declares %d shared files, expected 2", n)
+	}
+	// Shared files keep their own name.
+	info, err := archive.Stat("/copy/b.txt")
+	if err != nil || info.Name() != "b.txt" {
+		t.Errorf("shared file has info %v, %v", info, err)
+	}
+}
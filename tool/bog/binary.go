@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"flag"
+	"fmt"
+	"github.com/keimoon/bog"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExtractBinary lists or extracts the archives embedded in a compiled executable
+func ExtractBinary() int {
+	flags := flag.NewFlagSet("extract-binary", flag.ExitOnError)
+	list := flags.Bool("list", false, "List the archived files instead of extracting them")
+	asJSON := flags.Bool("json", false, "Print the list as JSON")
+	varName := flags.String("var", "", "Only handle the archive with this variable name, optionally qualified by its package import path")
+	format := flags.String("format", "dir", "Output format: dir, tar or zip")
+	output := flags.String("o", "-", "Output file for tar and zip formats, - for stdout")
+	flags.Parse(Args[1:])
+	if flags.NArg() != 1 {
+		Usage()
+		return 2
+	}
+	archives, err := loadBinary(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	names := []string{}
+	for name := range archives {
+		if *varName == "" || name == *varName || name[strings.LastIndex(name, ".")+1:] == *varName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		fmt.Fprintf(os.Stderr, "%s: no archive found\n", flags.Arg(0))
+		return 1
+	}
+	if !*list && *format != "dir" && len(names) > 1 {
+		fmt.Fprintf(os.Stderr, "%s: several archives found (%s), choose one with -var\n", flags.Arg(0), strings.Join(names, ", "))
+		return 1
+	}
+	for _, name := range names {
+		archive := archives[name]
+		if !*list {
+			err = exportArchive(archive, *format, *output)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			continue
+		}
+		entries, err := archiveEntries(archive)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !*asJSON {
+			fmt.Printf("%s:\n", name)
+		}
+		err = printEntries(entries, *asJSON)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// binaryFile is a file found in a compiled executable
+type binaryFile struct {
+	header *bog.FrameHeader
+	data   []byte
+}
+
+// loadBinary finds every archive embedded in a compiled executable, keyed by archiveName.
+// Folders are not framed, they are rebuilt from the paths of archived files.
+func loadBinary(filename string) (map[string]*bog.Archive, error) {
+	sections, err := binaryData(filename)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]map[string]*binaryFile)
+	magic := []byte(bog.FrameMagic)
+	for _, b := range sections {
+		for i := bytes.Index(b, magic); i >= 0; {
+			header, data, n, err := bog.ReadFrame(b[i:])
+			if err != nil || header.Archive == "" || !path.IsAbs(header.Path) {
+				n = 1
+			} else if header.Encoding == "" {
+				name := archiveName(header)
+				if found[name] == nil {
+					found[name] = make(map[string]*binaryFile)
+				}
+				found[name][header.Path] = &binaryFile{header: header, data: data}
+			}
+			next := bytes.Index(b[i+n:], magic)
+			if next < 0 {
+				break
+			}
+			i += n + next
+		}
+	}
+	for _, files := range found {
+		resolveShared(files)
+	}
+	archives := make(map[string]*bog.Archive)
+	for name, files := range found {
+		archives[name] = newBinaryArchive(files)
+	}
+	return archives, nil
+}
+
+// archiveName returns the variable name of the archive holding the frame described by header,
+// qualified by the import path of its package when recorded, such as example.com/app/assets.AssetsArchive.
+func archiveName(header *bog.FrameHeader) string {
+	if header.Package == "" {
+		return header.Archive
+	}
+	return header.Package + "." + header.Archive
+}
+
+// resolveShared gives the files whose frame holds no data the data of the file with the same hash.
+// They are dropped if that file is not found.
+func resolveShared(files map[string]*binaryFile) {
+	data := make(map[string][]byte)
+	for _, f := range files {
+		if !f.header.Shared {
+			data[f.header.Hash] = f.data
+		}
+	}
+	for name, f := range files {
+		if !f.header.Shared {
+			continue
+		}
+		if b, ok := data[f.header.Hash]; ok {
+			f.data = b
+		} else {
+			delete(files, name)
+		}
+	}
+}
+
+// binaryData returns the allocated sections of an ELF executable. Other
+// executable formats are read entirely.
+func binaryData(filename string) ([][]byte, error) {
+	f, err := elf.Open(filename)
+	if err != nil {
+		if _, ok := err.(*elf.FormatError); !ok {
+			return nil, err
+		}
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{b}, nil
+	}
+	defer f.Close()
+	sections := [][]byte{}
+	for _, section := range f.Sections {
+		if section.Type == elf.SHT_NOBITS || section.Flags&elf.SHF_ALLOC == 0 {
+			continue
+		}
+		b, err := section.Data()
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, b)
+	}
+	return sections, nil
+}
+
+func newBinaryArchive(files map[string]*binaryFile) *bog.Archive {
+	var root string
+	for _, f := range files {
+		root = f.header.Root
+		break
+	}
+	if f, ok := files["/"]; ok {
+		return bog.NewArchive(map[string]bog.File{"/": newBinaryFile(f)}, false, true, root)
+	}
+	children := make(map[string]map[string]bool)
+	for name := range files {
+		for name != "/" {
+			dir := path.Dir(name)
+			if children[dir] == nil {
+				children[dir] = make(map[string]bool)
+			}
+			if children[dir][name] {
+				break
+			}
+			children[dir][name] = true
+			name = dir
+		}
+	}
+	archiveFiles := make(map[string]bog.File)
+	var build func(name string) bog.File
+	build = func(name string) bog.File {
+		if f, ok := files[name]; ok {
+			archiveFiles[name] = newBinaryFile(f)
+			return archiveFiles[name]
+		}
+		names := []string{}
+		for child := range children[name] {
+			names = append(names, child)
+		}
+		sort.Strings(names)
+		folderChildren := []bog.File{}
+		for _, child := range names {
+			folderChildren = append(folderChildren, build(child))
+		}
+		folderName := path.Base(name)
+		if name == "/" {
+			folderName = path.Base(root)
+		}
+		archiveFiles[name] = bog.NewBogFolder(folderChildren, &bog.FileInfo{
+			FileName: folderName,
+			FileMode: os.ModeDir | 0755,
+		})
+		return archiveFiles[name]
+	}
+	build("/")
+	return bog.NewArchive(archiveFiles, false, false, root)
+}
+
+func newBinaryFile(f *binaryFile) bog.File {
+	return bog.NewBogFile(f.data, &bog.FileInfo{
+		FileName:        f.header.Name,
+		FileSize:        int64(len(f.data)),
+		FileMode:        f.header.Mode,
+		FileModTime:     time.Unix(f.header.ModTime, 0),
+		FileHash:        f.header.Hash,
+		FileContentType: f.header.ContentType,
+	})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"github.com/keimoon/bog"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// appendTestFrame appends the frame of a file of archive pkg.AssetsArchive to dst.
+func appendTestFrame(t *testing.T, dst []byte, pkg string, name string, data string) []byte {
+	dst = append(dst, "padding"...)
+	dst, err := bog.AppendFrame(dst, &bog.FrameHeader{
+		Archive: "AssetsArchive",
+		Package: pkg,
+		Root:    "assets",
+		Path:    "/" + name,
+		Name:    name,
+		Mode:    0644,
+		ModTime: 1500000000,
+	}, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+func TestLoadBinarySameVariableName(t *testing.T) {
+	var b []byte
+	b = appendTestFrame(t, b, "example.com/app/p1", "x.txt", "from p1")
+	b = appendTestFrame(t, b, "example.com/app/p2", "x.txt", "from p2")
+	b = appendTestFrame(t, b, "example.com/app/p2", "y.txt", "only p2")
+	filename := filepath.Join(t.TempDir(), "app")
+	err := ioutil.WriteFile(filename, b, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archives, err := loadBinary(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for name := range archives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "example.com/app/p1.AssetsArchive" || names[1] != "example.com/app/p2.AssetsArchive" {
+		t.Fatalf("found archives %v", names)
+	}
+	for name, expected := range map[string]map[string]string{
+		names[0]: {"/x.txt": "from p1"},
+		names[1]: {"/x.txt": "from p2", "/y.txt": "only p2"},
+	} {
+		infos, err := archives[name].ReadDir("/")
+		if err != nil || len(infos) != len(expected) {
+			t.Errorf("%s: ReadDir = %d files, %v, expected %d files", name, len(infos), err, len(expected))
+		}
+		for file, content := range expected {
+			data, err := archives[name].ReadFile(file)
+			if err != nil || string(data) != content {
+				t.Errorf("%s: %s holds %q, %v, expected %q", name, file, data, err, content)
+			}
+		}
+	}
+}
+
+func TestPackagePath(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("// app\nmodule \"example.com/app\" // main module\n\ngo 1.16\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "web", "assets")
+	err = os.MkdirAll(sub, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		dir      string
+		expected string
+	}{
+		{dir, "example.com/app"},
+		{sub, "example.com/app/web/assets"},
+	} {
+		if got := packagePath(test.dir, "assets"); got != test.expected {
+			t.Errorf("packagePath(%s) = %q, expected %q", test.dir, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"github.com/keimoon/bog"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// genCache keeps the rendered fragments of archived files between two generations of an archive,
+// so that unchanged files are neither checked, transformed nor rendered again. Files whose size, mode
+// and modification time did not change are hashed, and are unchanged if they still have the recorded
+// hash. Fragments are dropped when the parameters, the config files of the source or the template
+// change.
+//
+// A cache file holds the fragments, followed by the gob encoded index and its offset as a little
+// endian uint64. Only the index is loaded in memory. The cache is stored in the bog folder of the
+// user cache directory. BOGCACHE environment variable sets another folder, or disables the cache
+// when set to "off".
+type genCache struct {
+	filename string
+	header   string
+	dryRun   bool
+	// old is the previous cache file, and index its index.
+	old   *os.File
+	index *cacheIndex
+	// tmp is the next cache file, written while generating.
+	tmp     *os.File
+	w       *bufio.Writer
+	off     int64
+	entries map[string]*cacheEntry
+}
+
+type cacheIndex struct {
+	// Header holds the generation parameters, the import path of the package, the hash of the
+	// template and the hash of the config files of the source.
+	Header string
+	// Entries hold the cached files by path. Entries are kept for every file to report changes,
+	// but only files read from a folder have a fragment.
+	Entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	Size        int64
+	Mode        os.FileMode
+	ModTime     int64
+	VarName string
+	// SourceHash is the hash of the file read from the folder, and Hash the hash of the archived data,
+	// which differs from SourceHash for transformed files.
+	SourceHash  string
+	Hash        string
+	ContentType string
+	// DataSize is the size of the archived data, which differs from Size for transformed files.
+	DataSize int64
+	// Offset and Length locate the fragment in the cache file.
+	Offset int64
+	Length int64
+}
+
+// changes lists the paths of the files added, changed and removed since the previous generation.
+type changes struct {
+	// First is set when there was no previous generation to compare with.
+	First   bool
+	Added   []string
+	Changed []string
+	Removed []string
+	// Duplicates counts the files sharing the data of another file, and Saved the bytes of their data.
+	Duplicates int
+	Saved      int64
+}
+
+// openCache reads the index of the cache of the archive described by p. It returns nil when
+// the cache is disabled. A nil cache is valid, and caches nothing.
+func openCache(p *params, header string) *genCache {
+	dir := os.Getenv("BOGCACHE")
+	if dir == "off" {
+		return nil
+	}
+	if dir == "" {
+		userDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil
+		}
+		dir = filepath.Join(userDir, "bog")
+	}
+	output, err := filepath.Abs(p.Output)
+	if err != nil {
+		return nil
+	}
+	key := sha256.Sum256([]byte(output))
+	tmplHash, err := TemplatesArchive.Hash("main.go.tmpl")
+	if err != nil {
+		return nil
+	}
+	c := &genCache{
+		filename: filepath.Join(dir, hex.EncodeToString(key[:8])+".cache"),
+		header:   header + "\n" + p.pkgPath + "\n" + tmplHash,
+		dryRun:   p.dryRun,
+		entries:  make(map[string]*cacheEntry),
+	}
+	f, err := os.Open(c.filename)
+	if err != nil {
+		return c
+	}
+	index, err := readCacheIndex(f)
+	if err != nil {
+		f.Close()
+		return c
+	}
+	c.old = f
+	c.index = index
+	return c
+}
+
+func readCacheIndex(f *os.File) (*cacheIndex, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var trailer [8]byte
+	if info.Size() < 8 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	_, err = f.ReadAt(trailer[:], info.Size()-8)
+	if err != nil {
+		return nil, err
+	}
+	off := int64(binary.LittleEndian.Uint64(trailer[:]))
+	if off < 0 || off > info.Size()-8 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	index := &cacheIndex{}
+	err = gob.NewDecoder(io.NewSectionReader(f, off, info.Size()-8-off)).Decode(index)
+	return index, err
+}
+
+// addConfig adds the content of the config files of the source to the header, so that changing
+// them, for example the MIME types of .bogmime, drops the fragments.
+func (c *genCache) addConfig(config sourceConfig) {
+	if c == nil {
+		return
+	}
+	names := []string{}
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(config[name]))
+		h.Write(config[name])
+	}
+	c.header += "\n" + hex.EncodeToString(h.Sum(nil))
+}
+
+// lookup returns the cached entry of the file at archivePath, if its size, mode and modification
+// time did not change. The content is only compared by hit.
+func (c *genCache) lookup(archivePath string, info os.FileInfo, varName string) (*cacheEntry, bool) {
+	if c == nil || c.index == nil || c.index.Header != c.header {
+		return nil, false
+	}
+	entry, ok := c.index.Entries[archivePath]
+	if !ok || entry.Length == 0 || entry.VarName != varName || entry.Size != info.Size() ||
+		entry.Mode != info.Mode() || entry.ModTime != info.ModTime().UnixNano() {
+		return nil, false
+	}
+	return entry, true
+}
+
+// hit returns the cached fragment of fileVar, found by lookup, if its file still has the recorded
+// hash. The file is hashed without being kept in memory. fileVar then gets the hash, content type
+// and size of the archived data from the entry.
+func (c *genCache) hit(fileVar *FileVar) (string, bool, error) {
+	entry := fileVar.Cached
+	hash, err := hashFile(fileVar.File)
+	if err != nil {
+		return "", false, err
+	}
+	if hash != entry.SourceHash {
+		return "", false, nil
+	}
+	fragment, err := c.fragment(entry)
+	if err != nil {
+		return "", false, err
+	}
+	fileVar.sourceHash = hash
+	fileVar.Hash = entry.Hash
+	fileVar.ContentType = entry.ContentType
+	fileVar.Stat = &bog.FileInfo{
+		FileName:    fileVar.Source.Name(),
+		FileSize:    entry.DataSize,
+		FileMode:    fileVar.Source.Mode(),
+		FileModTime: fileVar.Source.ModTime(),
+	}
+	return fragment, true, nil
+}
+
+// hashFile returns the hex encoded SHA-256 hash of the content of filename.
+func hashFile(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fragment reads the fragment of a cached entry.
+func (c *genCache) fragment(entry *cacheEntry) (string, error) {
+	b := make([]byte, entry.Length)
+	_, err := c.old.ReadAt(b, entry.Offset)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", c.filename, err)
+	}
+	return string(b), nil
+}
+
+// add records the file for the next generation. Folders are not recorded, and the fragment is
+// only kept for files read from a folder.
+func (c *genCache) add(fileVar *FileVar, fragment string) error {
+	if c == nil || fileVar.IsDir {
+		return nil
+	}
+	entry := &cacheEntry{
+		VarName:     fileVar.VarName,
+		SourceHash:  fileVar.sourceHash,
+		Hash:        fileVar.Hash,
+		ContentType: fileVar.ContentType,
+		DataSize:    fileVar.Stat.Size(),
+	}
+	c.entries[fileVar.Path] = entry
+	if fileVar.Source == nil || c.dryRun {
+		return nil
+	}
+	err := c.create()
+	if err != nil {
+		return err
+	}
+	_, err = c.w.WriteString(fragment)
+	if err != nil {
+		return err
+	}
+	entry.Size = fileVar.Source.Size()
+	entry.Mode = fileVar.Source.Mode()
+	entry.ModTime = fileVar.Source.ModTime().UnixNano()
+	entry.Offset = c.off
+	entry.Length = int64(len(fragment))
+	c.off += entry.Length
+	return nil
+}
+
+// finish compares the recorded files with the previous generation, and writes the next cache
+// file, unless in dry run mode.
+func (c *genCache) finish(fileVars []*FileVar) *changes {
+	ch := &changes{First: c == nil || c.index == nil}
+	previous := make(map[string]*cacheEntry)
+	if !ch.First {
+		previous = c.index.Entries
+	}
+	current := make(map[string]bool)
+	for _, fileVar := range fileVars {
+		if fileVar.IsDir {
+			continue
+		}
+		current[fileVar.Path] = true
+		if old, ok := previous[fileVar.Path]; !ok {
+			ch.Added = append(ch.Added, fileVar.Path)
+		} else if old.Hash != fileVar.Hash {
+			ch.Changed = append(ch.Changed, fileVar.Path)
+		}
+	}
+	for path := range previous {
+		if !current[path] {
+			ch.Removed = append(ch.Removed, path)
+		}
+	}
+	sort.Strings(ch.Added)
+	sort.Strings(ch.Changed)
+	sort.Strings(ch.Removed)
+	if c != nil && !c.dryRun {
+		c.save()
+	}
+	c.close()
+	return ch
+}
+
+// save writes the index after the fragments, and replaces the previous cache file. Failing to
+// write the cache is not an error, the next generation is only slower.
+func (c *genCache) save() {
+	err := c.create()
+	if err != nil {
+		return
+	}
+	err = gob.NewEncoder(c.w).Encode(&cacheIndex{Header: c.header, Entries: c.entries})
+	if err != nil {
+		return
+	}
+	var trailer [8]byte
+	binary.LittleEndian.PutUint64(trailer[:], uint64(c.off))
+	_, err = c.w.Write(trailer[:])
+	if err == nil {
+		err = c.w.Flush()
+	}
+	if closeErr := c.tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if c.old != nil {
+		c.old.Close()
+		c.old = nil
+	}
+	if err == nil && os.Rename(c.tmp.Name(), c.filename) == nil {
+		c.tmp = nil
+	}
+}
+
+// create creates the next cache file, if not done yet.
+func (c *genCache) create() error {
+	if c.tmp != nil {
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(c.filename), 0755)
+	if err != nil {
+		return err
+	}
+	c.tmp, err = ioutil.TempFile(filepath.Dir(c.filename), filepath.Base(c.filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	c.w = bufio.NewWriter(c.tmp)
+	return nil
+}
+
+// close releases the cache files. The next cache file is removed if it was not saved.
+func (c *genCache) close() {
+	if c == nil {
+		return
+	}
+	if c.old != nil {
+		c.old.Close()
+		c.old = nil
+	}
+	if c.tmp != nil {
+		c.tmp.Close()
+		os.Remove(c.tmp.Name())
+		c.tmp = nil
+	}
+}
+
+// empty reports whether nothing changed.
+func (ch *changes) empty() bool {
+	return len(ch.Added) == 0 && len(ch.Changed) == 0 && len(ch.Removed) == 0
+}
+
+// print writes the changes of the archive generated to output, one path per line prefixed by
+// A, M or D. When there was no previous generation, only the number of files is written.
+func (ch *changes) print(w io.Writer, output string) {
+	shared := ""
+	if ch.Duplicates > 0 {
+		shared = fmt.Sprintf(", %d duplicates (%d bytes saved)", ch.Duplicates, ch.Saved)
+	}
+	if ch.First {
+		fmt.Fprintf(w, "%s: %d files%s\n", output, len(ch.Added), shared)
+		return
+	}
+	if ch.empty() {
+		return
+	}
+	fmt.Fprintf(w, "%s: %d added, %d changed, %d removed%s\n", output, len(ch.Added), len(ch.Changed), len(ch.Removed), shared)
+	lines := []string{}
+	for _, path := range ch.Added {
+		lines = append(lines, "A "+path)
+	}
+	for _, path := range ch.Changed {
+		lines = append(lines, "M "+path)
+	}
+	for _, path := range ch.Removed {
+		lines = append(lines, "D "+path)
+	}
+	fmt.Fprintln(w, "\t"+strings.Join(lines, "\n\t"))
+}
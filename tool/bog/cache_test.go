@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setenv sets the environment variable key for the duration of the test.
+func setenv(t *testing.T, key string, value string) {
+	old, ok := os.LookupEnv(key)
+	err := os.Setenv(key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// generateTest generates the archive of source to output, and returns the changes and the generated file.
+func generateTest(t *testing.T, source string, output string) (*changes, string) {
+	p := defaultParams(source, "assets", true)
+	p.Source = source
+	p.Output = output
+	_, ch, err := generate(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch, string(b)
+}
+
+func TestCacheSameSizeAndModTime(t *testing.T) {
+	dir := t.TempDir()
+	setenv(t, "BOGCACHE", filepath.Join(dir, "cache"))
+	source := filepath.Join(dir, "assets")
+	writeFiles(t, source, map[string]string{
+		"a.txt": "old content",
+		"b.txt": "unchanged",
+	})
+	output := filepath.Join(dir, "assets-archive.go")
+	ch, generated := generateTest(t, source, output)
+	if !ch.First || !strings.Contains(generated, "old content") {
+		t.Fatalf("first generation: %+v", ch)
+	}
+
+	// Rewrite a.txt with the same size, and restore its modification time.
+	filename := filepath.Join(source, "a.txt")
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filename, []byte("new content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chtimes(filename, info.ModTime(), info.ModTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, generated = generateTest(t, source, output)
+	if !reflect.DeepEqual(ch.Changed, []string{"/a.txt"}) || len(ch.Added) != 0 || len(ch.Removed) != 0 {
+		t.Errorf("second generation: %+v, expected /a.txt to be changed", ch)
+	}
+	if strings.Contains(generated, "old content") || !strings.Contains(generated, "new content") {
+		t.Errorf("the generated file holds the previous content of a.txt")
+	}
+
+	ch, again := generateTest(t, source, output)
+	if !ch.empty() || again != generated {
+		t.Errorf("third generation: %+v, expected no change", ch)
+	}
+}
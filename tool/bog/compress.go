@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/keimoon/bog"
+	"net/http"
+	"strings"
+)
+
+// incompressibleTypes are content types which are already compressed
+var incompressibleTypes = []string{
+	"image/", "audio/", "video/", "font/woff", "font/woff2",
+	"application/zip", "application/x-gzip", "application/x-rar-compressed", "application/pdf",
+}
+
+// compressible reports whether data is worth compressing.
+func compressible(data []byte) bool {
+	if len(data) < 256 {
+		return false
+	}
+	ctype := http.DetectContentType(data)
+	if strings.HasPrefix(ctype, "image/svg") {
+		return true
+	}
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(ctype, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// appendGzipFrame appends the frame of the gzip compressed variant of fileVar to frame, if fileVar
+// is compressible and compressing saves at least a tenth of its size.
+func appendGzipFrame(frame []byte, p *params, fileVar *FileVar) ([]byte, error) {
+	if !compressible(fileVar.Data) {
+		return frame, nil
+	}
+	buf := &bytes.Buffer{}
+	w, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(fileVar.Data)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() > len(fileVar.Data)*9/10 {
+		return frame, nil
+	}
+	return bog.AppendFrame(frame, &bog.FrameHeader{
+		Archive:  p.Var,
+		Root:     p.Root,
+		Path:     fileVar.Path,
+		Name:     fileVar.Stat.Name(),
+		Mode:     fileVar.Stat.Mode(),
+		ModTime:  fileVar.Stat.ModTime().Unix(),
+		Hash:     fileVar.Hash,
+		Encoding: "gzip",
+	}, buf.Bytes())
+}
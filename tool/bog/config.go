@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// projectConfig is the file describing the archives built by "bog archive" without arguments.
+const projectConfig = "bog.json"
+
+// configEntry describes an archive in the project config. Paths are relative to the folder of the config.
+type configEntry struct {
+	// Source is the archived folder, file, tar or zip file.
+	Source string `json:"source"`
+	// Sources replace Source to archive several sources, each one mounted at its own path.
+	Sources     []*mount          `json:"sources"`
+	Package     string            `json:"package"`
+	Output      string            `json:"output"`
+	Var         string            `json:"var"`
+	Root        string            `json:"root"`
+	Dev         bool              `json:"dev"`
+	Ignore      []string          `json:"ignore"`
+	Gzip        bool              `json:"gzip"`
+	Fingerprint bool              `json:"fingerprint"`
+	Validate    bool              `json:"validate"`
+	Checks      map[string]string `json:"checks"`
+	Transforms  []*transform      `json:"transforms"`
+	Mime        map[string]string `json:"mime"`
+	Embed       bool              `json:"embed"`
+	Raw         bool              `json:"raw"`
+	SplitSize   sizeFlag          `json:"splitSize"`
+}
+
+var identRegex = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+
+// loadConfig reads the project config filename, and returns the params of its archives.
+// Errors point to the offending entry.
+func loadConfig(filename string) ([]*params, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	config := &struct {
+		Archives []json.RawMessage `json:"archives"`
+	}{}
+	err = decodeStrict(b, config)
+	if err != nil {
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			return nil, fmt.Errorf("%s:%d: %v", filename, lineAt(b, syntaxErr.Offset), err)
+		}
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if len(config.Archives) == 0 {
+		return nil, errors.New(filename + ": no archives")
+	}
+	dir := filepath.Dir(filename)
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	cwdPackage := makePackageName(filepath.Base(absDir))
+	allParams := []*params{}
+	outputs := make(map[string]int)
+	vars := make(map[string]int)
+	for i, raw := range config.Archives {
+		entry := &configEntry{}
+		err = decodeStrict(raw, entry)
+		if err == nil {
+			var p *params
+			p, err = entry.params(dir, cwdPackage)
+			if err == nil {
+				output := filepath.Clean(p.Output)
+				varKey := filepath.Dir(output) + ":" + p.Var
+				if j, ok := outputs[output]; ok {
+					err = fmt.Errorf("output %s already written by archives[%d]", p.Output, j)
+				} else if j, ok := vars[varKey]; ok {
+					err = fmt.Errorf("var %s already declared by archives[%d]", p.Var, j)
+				}
+				outputs[output] = i
+				vars[varKey] = i
+				allParams = append(allParams, p)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: archives[%d]: %v", filename, i, err)
+		}
+	}
+	return allParams, nil
+}
+
+// decodeStrict decodes the JSON value b into v, rejecting unknown fields.
+func decodeStrict(b []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// params checks the entry, and returns the params of the archive. Paths are resolved from dir.
+func (e *configEntry) params(dir string, cwdPackage string) (*params, error) {
+	if (e.Source == "") == (len(e.Sources) == 0) {
+		return nil, errors.New("exactly one of source and sources is required")
+	}
+	if e.Source == stdinSource {
+		return nil, errors.New("source cannot be stdin")
+	}
+	packageName := e.Package
+	if packageName == "" {
+		packageName = cwdPackage
+	} else if !identRegex.MatchString(packageName) {
+		return nil, fmt.Errorf("invalid package %q", packageName)
+	}
+	if e.Var != "" && !identRegex.MatchString(e.Var) {
+		return nil, fmt.Errorf("invalid var %q", e.Var)
+	}
+	if e.Output != "" && !strings.HasSuffix(e.Output, ".go") {
+		return nil, fmt.Errorf("output %q is not a Go file", e.Output)
+	}
+	for ext := range e.Mime {
+		if ext == "" || strings.ContainsAny(ext, "/ ") {
+			return nil, fmt.Errorf("invalid mime extension %q", ext)
+		}
+	}
+	for glob := range e.Checks {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("checks: %q: %v", glob, err)
+		}
+	}
+	for i, t := range e.Transforms {
+		if _, err := path.Match(t.Glob, ""); err != nil || t.Glob == "" {
+			return nil, fmt.Errorf("transforms[%d]: invalid glob %q", i, t.Glob)
+		}
+		if t.Command == "" {
+			return nil, fmt.Errorf("transforms[%d]: missing command", i)
+		}
+	}
+	root := e.Root
+	if root == "" {
+		root = packageName
+		if e.Source != "" {
+			root = trimArchiveExt(filepath.ToSlash(filepath.Clean(e.Source)))
+		}
+	}
+	p := defaultParams(root, packageName, packageName == cwdPackage)
+	if e.Var != "" {
+		p.Var = e.Var
+	}
+	if e.Output != "" {
+		p.Output = e.Output
+	}
+	p.Output = filepath.Join(dir, p.Output)
+	if e.Source != "" {
+		p.Source = filepath.Join(dir, filepath.FromSlash(e.Source))
+	}
+	for i, m := range e.Sources {
+		if m.Source == "" || m.Source == stdinSource {
+			return nil, fmt.Errorf("sources[%d]: invalid source %q", i, m.Source)
+		}
+		if e.Dev {
+			return nil, errMountsDev
+		}
+		p.Mounts = append(p.Mounts, &mount{
+			Source: filepath.Join(dir, filepath.FromSlash(m.Source)),
+			Path:   path.Clean("/" + m.Path),
+		})
+	}
+	if e.Dev && isArchiveSource(e.Source) {
+		return nil, errArchiveDev
+	}
+	if e.Dev && e.Fingerprint {
+		return nil, errors.New("cannot write a manifest in development mode")
+	}
+	p.Dev = e.Dev
+	p.Ignore = e.Ignore
+	p.Gzip = e.Gzip
+	p.Fingerprint = e.Fingerprint
+	p.Validate = e.Validate
+	p.Checks = e.Checks
+	p.Transforms = e.Transforms
+	p.Mime = e.Mime
+	p.Embed = e.Embed
+	p.Raw = e.Raw
+	p.SplitSize = int64(e.SplitSize)
+	return p, nil
+}
@@ -21,6 +21,16 @@ Or shorter:
   bog a /path/to/directory
   bog a /path/to/file
 
+Archiving tar and zip files
+
+Files can also be archived from a tar file, possibly compressed with gzip, or a zip file.
+Modes and modification times are taken from the headers of the entries:
+  bog archive dist.tar.gz
+  bog archive dist.zip
+
+Use "-" to read a tar stream from stdin. In this case, the name of the archive is the package name:
+  tar c -C dist . | bog -p assets archive -
+
 Extracting
 
 To extract the data of archived source file to current directory:
@@ -29,11 +39,187 @@ To extract the data of archived source file to current directory:
 Or:
   bog e directory-archive.go
 
+To write the content as a tar or zip file instead, use -format and -o switches:
+  bog extract -format tar -o directory.tar directory-archive.go
+
+If the source file contains several archives, choose the one to extract with -var switch:
+  bog extract -var MyFolderArchive directory-archive.go
+
+Extracting from a compiled executable
+
+Archived files are stored with a recognizable header, so they can be recovered from a compiled
+executable even without its source. To list the archives of an executable:
+  bog extract-binary -list ./myapp
+
+To extract them to current directory:
+  bog extract-binary ./myapp
+
+Archives are named by their variable name qualified by the import path of their package, such as
+example.com/app/assets.AssetsArchive, so that archives of different packages with the same variable
+name are kept apart. Use -var switch with the qualified name, or the variable name when it is
+unique, to only handle one of the archives. Folders are rebuilt from the paths of the files,
+therefore empty folders are lost.
+
+Inspecting
+
+To list the files of an archived source file with their size, mode and modification time:
+  bog list directory-archive.go
+
+Use -json switch to print the list as JSON:
+  bog list -json directory-archive.go
+
+To print the content of a single archived file:
+  bog cat directory-archive.go /path/to/file
+
+Regenerating
+
+Generated files record the parameters used to create them. To regenerate every archive
+in the current folder and its sub folders:
+  bog regen ./...
+
+To only list the archives which are out of date, without writing them:
+  bog regen -check ./...
+
+Incremental generation
+
+The generator keeps a cache of the rendered files of every archive, so that unchanged files are neither
+checked, transformed, compressed nor rendered again. Files whose size, mode and modification time did
+not change are read to compare their hash with the recorded one, so a file rewritten with the same size
+and modification time is still rendered again. The generated file is only written when its content
+changes, and the added, changed and removed files are reported. Changing the switches or the .bogmime file renders every file again. The cache is
+stored in the bog folder of the user cache directory. Set BOGCACHE environment variable to use another
+folder, or to "off" to disable the cache.
+
+Files are read, rendered and compressed in parallel, and written in order, so that the generated file does
+not depend on scheduling. Generating an archive only needs the memory of the files processed at once.
+Entries of zip sources are read from the zip file, and tar streams are first copied to a temporary file.
+Use -j switch to set the number of files processed at once, the number of CPUs by default:
+  bog -j 4 a /path/to/directory
+
+Readable diffs
+
+To write text files as raw string literals, so that every line of a file is a line of the generated file
+and diffs of generated files show the changed lines:
+  bog -r a /path/to/directory
+
+Files which are not valid UTF-8, or hold backquotes, carriage returns or control characters other than
+tabs, keep the quoted form.
+
+Splitting large archives
+
+To move the archived files to shards of about 50MB, named directory-archive_001.go, directory-archive_002.go
+and so on, next to the generated file keeping the folders and the archive variable:
+  bog -split-size 50MB a /path/to/directory
+
+Shards left by a previous generation are removed. Extracting, listing and serving read the whole set,
+given the generated file or any of its shards.
+
+Duplicate files
+
+Files with the same content are generated once, and shared by the other files, which keep their own
+name, size, mode and modification time. The number of duplicates and the bytes saved are reported.
+
+Embedding with go:embed
+
+To declare the archive over files embedded with //go:embed directives, instead of writing their data in the
+generated file:
+  bog -p main -embed a public
+
+The archived folder or file must be inside the folder of the generated file. Files are listed by name, so
+that ignored files are left out. Their sizes, modes, modification times, hashes and content types are
+recorded in a table, with the folders, since embed.FS does not keep them. Several sources, tar and zip
+sources, -t, -z, -r and -split-size switches are not supported.
+
+Watching
+
+To archive a folder, then generate the archive again each time its files change:
+  bog -p assets watch /path/to/directory
+
+Ignored files are not watched, and the generated file is only written when its content changes.
+Use -interval and -debounce switches to change the time between two scans, and how long files must
+be left unchanged before regenerating. Without argument, the archives of bog.json are watched.
+Interrupt it, for example with Ctrl-C, to stop watching: a generation in progress is finished first.
+
+Previewing
+
+To serve a folder, file, tar or zip file, or the archive of a generated file over HTTP, as Server would:
+  bog -z serve -addr :8080 /path/to/directory
+  bog serve directory-archive.go
+
+Folders are archived in memory with the other switches, and archived again when their files change.
+Use -fallback, -404, -list and -hide-dotfiles switches to set the options of Server. The /_bog/ page
+lists the archived files with their sizes and content types.
+
+Project config
+
+A bog.json file describes the archives of a project. Run "bog archive" without argument in its folder
+to build all of them:
+
+  {
+    "archives": [
+      {"source": "templates", "package": "views", "validate": true},
+      {
+        "sources": [{"source": "public", "path": "/"}, {"source": "dist.zip", "path": "/static"}],
+        "package": "main", "var": "SiteArchive", "output": "site-archive.go", "gzip": true
+      }
+    ]
+  }
+
+An entry archives either one source, or several sources, each one mounted at its own path. Other fields
+are package, output, var, root, dev, ignore, gzip, fingerprint, validate, checks, transforms, mime, raw,
+splitSize and embed, matching the command line switches. Paths are relative to the folder of bog.json. Errors
+name the offending entry.
+
 Ignore files
 
 Bog supports the use of special file called .bogignore to make the generator ignore certain files
 or folders. It is nearly identical to .gitignore.
 
+The rules of .bogignore files found in tar and zip files apply in the same way. Additional rules can
+be read from a file with -i switch:
+
+  bog -i my-ignore-rules a /path/to/directory
+
+MIME types
+
+The MIME type of every archived file is recorded in the generated file. To choose the MIME type of
+unusual extensions, put a .bogmime file at the root of the archived folder, tar or zip file, with one
+extension and its MIME type per line. Lines starting with # are comments. .bogmime files of other
+folders are not read, and are archived as any other file:
+
+  .glsl text/plain; charset=utf-8
+
+Validating files
+
+With -validate switch, the template syntax of .tmpl and .html files is checked, and JSON and XML files
+are parsed before archiving. The generation fails if some of them are malformed. Problems are reported
+as file:line: message. Template functions are not checked, because they are only known by the program
+using the templates. Neither are the contexts escaped by html/template, which are only known when
+executing the templates:
+
+  bog -validate a /path/to/directory
+
+Use -c switch to run your own checks on the files matched by a glob. The command is run with the shell,
+reads the file from stdin, and finds its archived path in BOG_PATH environment variable. A non-zero exit
+status fails the generation. Globs without slash match the base name of files:
+
+  bog -c '*.js=node --check -' a /path/to/directory
+
+Transforming files
+
+Use -t switch to change the content of the files matched by a glob before archiving them, for example to
+minify them. The switch can be repeated, and transforms are applied in order. The recorded size is the
+size of the result:
+
+  bog -t '*.css=minify-css' -t '*.json=minify-json' -t '*.txt=normalize-eol' a /path/to/directory
+
+Built-in transforms are minify-css and minify-json, which remove useless whitespace, and normalize-eol,
+which replaces CRLF line endings with LF. Any other value is a command run with the shell, reading the
+file from stdin and writing the result to stdout. Files are validated before being transformed, and
+transforms do not apply in development mode:
+
+  bog -t '*.js=terser --compress' a /path/to/directory
+
 Setting package name
 
 By default, bog will use current directory for package name in generated files. To change that, use -p switch:
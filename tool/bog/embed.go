@@ -0,0 +1,161 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// checkEmbed returns an error if the archive described by p cannot be generated with //go:embed.
+// Embedded files are stored as they are, inside the folder of the generated file.
+func checkEmbed(p *params) error {
+	switch {
+	case len(p.Mounts) > 0:
+		return errors.New("embed: cannot embed several sources")
+	case isArchiveSource(p.Source):
+		return errors.New("embed: cannot embed a tar or zip file")
+	case len(p.Transforms) > 0:
+		return errors.New("embed: cannot transform embedded files")
+	case p.Gzip:
+		return errors.New("embed: cannot store gzip compressed variants of embedded files")
+	case p.Raw:
+		return errors.New("embed: cannot write embedded files as raw string literals")
+	case p.SplitSize > 0:
+		return errors.New("embed: cannot split embedded files")
+	}
+	_, err := embedDir(p)
+	return err
+}
+
+// embedDir returns the slash separated path of the source of p, relative to the folder of the
+// generated file, which must hold it.
+func embedDir(p *params) (string, error) {
+	outputFolder, err := filepath.Abs(filepath.Dir(p.Output))
+	if err != nil {
+		return "", err
+	}
+	source, err := filepath.Abs(p.Source)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(outputFolder, source)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("embed: " + p.Source + " is not inside the folder of " + p.Output)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// generateEmbed writes the Go source declaring the archive described by p over an embed.FS. Files
+// are embedded by name, so that ignored files are left out. They are read one at a time to record
+// their hashes and content types, with the modes and modification times of files and folders, which
+// embed.FS does not keep.
+func generateEmbed(p *params, header string) ([]string, *changes, error) {
+	err := checkEmbed(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir, err := embedDir(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	fileVars, mimeTypes, isFile, err := collect(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	var problems validationError
+	patterns := []string{}
+	for _, fileVar := range fileVars {
+		if fileVar.IsDir {
+			continue
+		}
+		if strings.ContainsAny(path.Base(fileVar.Path), "\"'*<>?`|:\\") {
+			return nil, nil, errors.New("embed: " + sourceName(p, fileVar) + ": file name cannot be embedded")
+		}
+		fileVar.Data, err = ioutil.ReadFile(fileVar.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		problems = append(problems, validateFile(p, fileVar)...)
+		hash := sha256.Sum256(fileVar.Data)
+		fileVar.Hash = hex.EncodeToString(hash[:])
+		fileVar.ContentType = mimeTypes.detect(fileVar.Stat.Name(), fileVar.Data)
+		fileVar.Data = nil
+		patterns = append(patterns, embedPattern(path.Join(dir, fileVar.Path)))
+	}
+	if len(problems) > 0 {
+		return nil, nil, problems
+	}
+	if isFile {
+		patterns = []string{embedPattern(dir)}
+	}
+	mainTmpl, err := loadTemplate("main.go.tmpl")
+	if err != nil {
+		return nil, nil, err
+	}
+	out := newOutputWriter(p.Output, p.dryRun)
+	err = mainTmpl.ExecuteTemplate(out, "embed", &struct {
+		Params      string
+		PackageName string
+		Patterns    []string
+		Files       []*FileVar
+		FSVar       string
+		Dir         string
+		Root        string
+		VarName     string
+		IsFile      bool
+		Dev         bool
+	}{
+		Params:      header,
+		PackageName: p.Package,
+		Patterns:    patterns,
+		Files:       fileVars,
+		FSVar:       makeVariableName(p.Var + "_FS"),
+		Dir:         dir,
+		Root:        p.Root,
+		VarName:     p.Var,
+		IsFile:      isFile,
+		Dev:         p.Dev,
+	})
+	if err != nil {
+		out.abort()
+		return nil, nil, err
+	}
+	written := []string{}
+	changed, err := out.commit()
+	if err != nil {
+		return nil, nil, err
+	}
+	if changed {
+		written = append(written, p.Output)
+	}
+	if p.Fingerprint {
+		manifest, err := makeManifest(fileVars, isFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		changed, err := writeIfChanged(p.manifestName(), manifest, p.dryRun)
+		if err != nil {
+			return nil, nil, err
+		}
+		if changed {
+			written = append(written, p.manifestName())
+		}
+	}
+	var cache *genCache
+	return written, cache.finish(fileVars), nil
+}
+
+// embedPattern returns the //go:embed pattern matching the file name only. Names holding
+// the other glob characters cannot be embedded.
+func embedPattern(name string) string {
+	pattern := strings.Replace(name, "[", "[[]", -1)
+	if strings.ContainsAny(pattern, " \t") {
+		return strconv.Quote(pattern)
+	}
+	return pattern
+}
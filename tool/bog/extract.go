@@ -3,228 +3,579 @@ package main
 import (
 	"bytes"
 	"errors"
+	"flag"
 	"fmt"
 	"github.com/keimoon/bog"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
+const bogImportPath = "github.com/keimoon/bog"
+
 // Extract extracts content of an archived go source file to current folder
 func Extract() int {
-	fset := token.NewFileSet()
-	sourceFile := Args[1]
-	f, err := parser.ParseFile(fset, sourceFile, nil, 0)
+	flags := flag.NewFlagSet("extract", flag.ExitOnError)
+	varName := flags.String("var", "", "Name of the archive variable, if the file contains several archives")
+	format := flags.String("format", "dir", "Output format: dir, tar or zip")
+	output := flags.String("o", "-", "Output file for tar and zip formats, - for stdout")
+	flags.Parse(Args[1:])
+	if flags.NArg() != 1 {
+		Usage()
+		return 2
+	}
+	archive, err := loadArchive(flags.Arg(0), *varName)
 	if err != nil {
 		fmt.Println(err)
 		return 2
 	}
-	files := make(map[string]bog.File)
-	var archive *bog.Archive
-	for _, decl := range f.Decls {
-		genDecl, ok := decl.(*ast.GenDecl)
-		if !ok {
+	err = exportArchive(archive, *format, *output)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	return 0
+}
+
+// exportArchive extracts archive to current folder, or writes it to output as a tar or zip file.
+func exportArchive(archive *bog.Archive, format string, output string) (err error) {
+	var write func(io.Writer) error
+	switch format {
+	case "dir":
+		return archive.Extract()
+	case "tar":
+		write = archive.WriteTar
+	case "zip":
+		write = archive.WriteZip
+	default:
+		return errors.New("unknown format " + format)
+	}
+	if output == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return write(f)
+}
+
+// loadArchive loads the archive named varName from a generated go source file and its shards.
+// A shard can be given instead of the generated file. If varName is empty, the file must contain
+// exactly one archive.
+func loadArchive(sourceFile string, varName string) (*bog.Archive, error) {
+	if index := shardIndex(sourceFile); index != "" {
+		sourceFile = index
+	}
+	l := newLoader()
+	err := l.parseFile(sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := shardNames(sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, shard := range shards {
+		err = l.parseFile(shard)
+		if err != nil {
+			return nil, err
+		}
+	}
+	names := l.archiveNames()
+	if varName == "" {
+		switch len(names) {
+		case 0:
+			return nil, fmt.Errorf("%s: no archive found", sourceFile)
+		case 1:
+			varName = names[0]
+		default:
+			return nil, fmt.Errorf("%s: several archives found (%s), choose one with -var", sourceFile, strings.Join(names, ", "))
+		}
+	}
+	call, ok := l.calls[varName]
+	if !ok || !isArchiveCall(call.fun) {
+		return nil, fmt.Errorf("%s: archive %s not found", sourceFile, varName)
+	}
+	if call.fun == "NewFSArchive" {
+		return l.createFSArchive(call.expr, filepath.Dir(sourceFile))
+	}
+	return l.createArchive(call.expr)
+}
+
+// loader rebuilds archives from the AST of generated files. Variables are recognized by the bog
+// constructor they call, whatever their names and declaration order are.
+type loader struct {
+	fset    *token.FileSet
+	calls   map[string]*bogCall
+	files   map[string]bog.File
+	loading map[string]bool
+}
+
+// bogCall is a call to a bog constructor assigned to a package variable.
+type bogCall struct {
+	fun  string
+	expr *ast.CallExpr
+}
+
+func newLoader() *loader {
+	return &loader{
+		fset:    token.NewFileSet(),
+		calls:   make(map[string]*bogCall),
+		files:   make(map[string]bog.File),
+		loading: make(map[string]bool),
+	}
+}
+
+func (l *loader) errorf(node ast.Node, format string, args ...interface{}) error {
+	return fmt.Errorf("%s: "+format, append([]interface{}{l.fset.Position(node.Pos())}, args...)...)
+}
+
+// parseFile collects the calls to bog constructors of a generated go source file.
+func (l *loader) parseFile(sourceFile string) error {
+	f, err := parser.ParseFile(l.fset, sourceFile, nil, 0)
+	if err != nil {
+		return err
+	}
+	bogName := ""
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || path != bogImportPath {
 			continue
 		}
-		if genDecl.Tok != token.VAR {
+		bogName = "bog"
+		if imp.Name != nil {
+			bogName = imp.Name.Name
+		}
+	}
+	if bogName == "" {
+		return fmt.Errorf("%s: %s is not imported", sourceFile, bogImportPath)
+	}
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
 			continue
 		}
 		for _, spec := range genDecl.Specs {
 			valueSpec, ok := spec.(*ast.ValueSpec)
-			if !ok {
+			if !ok || len(valueSpec.Names) != len(valueSpec.Values) {
 				continue
 			}
-			if len(valueSpec.Names) <= 0 || len(valueSpec.Values) <= 0 {
-				continue
-			}
-			varName := valueSpec.Names[0].Name
-			callExpr, ok := valueSpec.Values[0].(*ast.CallExpr)
-			if !ok {
-				continue
-			}
-			selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
-			if !ok {
-				continue
-			}
-			if strings.HasPrefix(varName, "vvv") {
-				var file bog.File
-				if selectorExpr.Sel.Name == "NewBogFile" {
-					file, err = createBogFile(callExpr.Args)
-				} else {
-					file, err = createBogFolder(files, callExpr.Args)
+			for i, name := range valueSpec.Names {
+				callExpr, ok := valueSpec.Values[i].(*ast.CallExpr)
+				if !ok {
+					continue
 				}
-				if err != nil {
-					fmt.Println(err)
-					return 2
+				fun := selectorName(callExpr.Fun, bogName)
+				switch fun {
+				case "NewBogFile", "NewFramedFile", "NewSharedFile", "NewBogFolder", "NewArchive", "NewFSArchive":
+				default:
+					continue
 				}
-				files[varName] = file
-			} else {
-				archive, err = createArchive(files, callExpr.Args)
-				if err != nil {
-					fmt.Println(err)
-					return 2
+				if _, ok := l.calls[name.Name]; ok {
+					return l.errorf(name, "%s redeclared", name.Name)
 				}
+				l.calls[name.Name] = &bogCall{fun: fun, expr: callExpr}
 			}
 		}
 	}
-	err = archive.Extract()
-	if err != nil {
-		fmt.Println(err)
-		return 2
+	return nil
+}
+
+// selectorName returns the name of the selected function if expr is pkg.Func, or an empty string.
+func selectorName(expr ast.Expr, pkg string) string {
+	selectorExpr, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return ""
 	}
-	return 0
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	if !ok || ident.Name != pkg {
+		return ""
+	}
+	return selectorExpr.Sel.Name
 }
 
-func createBogFile(args []ast.Expr) (bog.File, error) {
-	if len(args) != 2 {
-		return nil, errors.New("malformed source file, NewBogFile has exactly 2 arguments")
+// isArchiveCall returns true if fun is a bog constructor of archives.
+func isArchiveCall(fun string) bool {
+	return fun == "NewArchive" || fun == "NewFSArchive"
+}
+
+// archiveNames returns the sorted names of archive variables found in the parsed files.
+func (l *loader) archiveNames() []string {
+	names := []string{}
+	for name, call := range l.calls {
+		if isArchiveCall(call.fun) {
+			names = append(names, name)
+		}
 	}
-	firstArg, ok := args[0].(*ast.CompositeLit)
+	sort.Strings(names)
+	return names
+}
+
+// file returns the file assigned to the variable referenced by expr.
+func (l *loader) file(expr ast.Expr) (bog.File, error) {
+	ident, ok := expr.(*ast.Ident)
 	if !ok {
-		return nil, errors.New("malformed source file, first argument of NewBogFile must be a CompositeLit")
+		return nil, l.errorf(expr, "malformed source file, file must be a variable")
 	}
-	buffer := &bytes.Buffer{}
-	for _, elt := range firstArg.Elts {
-		basicLit, ok := elt.(*ast.BasicLit)
-		if !ok {
-			return nil, errors.New("malformed source file, data invalid")
-		}
-		val, err := strconv.ParseInt(basicLit.Value, 0, 64)
+	if f, ok := l.files[ident.Name]; ok {
+		return f, nil
+	}
+	call, ok := l.calls[ident.Name]
+	if !ok || isArchiveCall(call.fun) {
+		return nil, l.errorf(expr, "malformed source file, file not found: %s", ident.Name)
+	}
+	if l.loading[ident.Name] {
+		return nil, l.errorf(expr, "malformed source file, %s contains itself", ident.Name)
+	}
+	l.loading[ident.Name] = true
+	defer delete(l.loading, ident.Name)
+	var f bog.File
+	var err error
+	switch call.fun {
+	case "NewBogFile", "NewFramedFile":
+		f, err = l.createBogFile(call)
+	case "NewSharedFile":
+		f, err = l.createSharedFile(call.expr)
+	default:
+		f, err = l.createBogFolder(call.expr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.files[ident.Name] = f
+	return f, nil
+}
+
+func (l *loader) createBogFile(call *bogCall) (bog.File, error) {
+	if len(call.expr.Args) != 2 {
+		return nil, l.errorf(call.expr, "malformed source file, %s has exactly 2 arguments", call.fun)
+	}
+	var data []byte
+	var err error
+	if call.fun == "NewFramedFile" {
+		var frame string
+		frame, err = l.parseString(call.expr.Args[0])
 		if err != nil {
 			return nil, err
 		}
-		err = buffer.WriteByte(byte(val))
+		_, data, _, err = bog.ReadFrame([]byte(frame))
+		if err != nil {
+			return nil, l.errorf(call.expr.Args[0], "%v", err)
+		}
+	} else {
+		data, err = l.parseData(call.expr.Args[0])
 		if err != nil {
 			return nil, err
 		}
 	}
-	stat, err := parseStat(args[1])
+	stat, err := l.parseStat(call.expr.Args[1])
 	if err != nil {
 		return nil, err
 	}
-	return bog.NewBogFile(buffer.Bytes(), stat), nil
+	return bog.NewBogFile(data, stat), nil
 }
 
-func createBogFolder(files map[string]bog.File, args []ast.Expr) (bog.File, error) {
-	if len(args) != 2 {
-		return nil, errors.New("malformed source file, NewBogFile has exactly 2 arguments")
+func (l *loader) createSharedFile(call *ast.CallExpr) (bog.File, error) {
+	if len(call.Args) != 3 {
+		return nil, l.errorf(call, "malformed source file, NewSharedFile has exactly 3 arguments")
+	}
+	frame, err := l.parseString(call.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	_, _, _, err = bog.ReadFrame([]byte(frame))
+	if err != nil {
+		return nil, l.errorf(call.Args[0], "%v", err)
 	}
-	firstArg, ok := args[0].(*ast.CompositeLit)
+	shared, err := l.file(call.Args[1])
+	if err != nil {
+		return nil, err
+	}
+	sharedStat, err := shared.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if sharedStat.IsDir() {
+		return nil, l.errorf(call.Args[1], "malformed source file, shared file is a folder")
+	}
+	data := make([]byte, sharedStat.Size())
+	_, err = shared.ReadAt(data, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	stat, err := l.parseStat(call.Args[2])
+	if err != nil {
+		return nil, err
+	}
+	return bog.NewBogFile(data, stat), nil
+}
+
+func (l *loader) createBogFolder(call *ast.CallExpr) (bog.File, error) {
+	if len(call.Args) != 2 {
+		return nil, l.errorf(call, "malformed source file, NewBogFolder has exactly 2 arguments")
+	}
+	firstArg, ok := call.Args[0].(*ast.CompositeLit)
 	if !ok {
-		return nil, errors.New("malformed source file, first argument of NewBogFile must be a CompositeLit")
+		return nil, l.errorf(call.Args[0], "malformed source file, first argument of NewBogFolder must be a CompositeLit")
 	}
 	children := []bog.File{}
 	for _, elt := range firstArg.Elts {
-		ident, ok := elt.(*ast.Ident)
-		if !ok {
-			return nil, errors.New("malformed source file, file array invalid")
-		}
-		f, ok := files[ident.Name]
-		if !ok {
-			return nil, errors.New("malformed source file, cannot file ident " + ident.Name)
+		f, err := l.file(elt)
+		if err != nil {
+			return nil, err
 		}
 		children = append(children, f)
 	}
-	stat, err := parseStat(args[1])
+	stat, err := l.parseStat(call.Args[1])
 	if err != nil {
 		return nil, err
 	}
 	return bog.NewBogFolder(children, stat), nil
 }
 
-func createArchive(files map[string]bog.File, args []ast.Expr) (*bog.Archive, error) {
-	if len(args) != 4 {
-		return nil, errors.New("malformed source file, NewArchive has exactly 4 arguments")
+func (l *loader) createArchive(call *ast.CallExpr) (*bog.Archive, error) {
+	if len(call.Args) != 4 {
+		return nil, l.errorf(call, "malformed source file, NewArchive has exactly 4 arguments")
 	}
-	secondArgs, ok := args[1].(*ast.Ident)
-	if !ok {
-		return nil, errors.New("malformed source file, second argument of NewArchive must be an Ident")
+	dev, err := l.parseBool(call.Args[1])
+	if err != nil {
+		return nil, err
 	}
-	if secondArgs.Name == "true" {
-		return nil, errors.New("cannot extract source file in development mode")
+	if dev {
+		return nil, l.errorf(call.Args[1], "cannot extract source file in development mode")
+	}
+	firstArg, ok := call.Args[0].(*ast.CompositeLit)
+	if !ok {
+		return nil, l.errorf(call.Args[0], "malformed source file, first argument of NewArchive must be a CompositeLit")
 	}
-	firstArg, ok := args[0].(*ast.CompositeLit)
-        if !ok {
-                return nil, errors.New("malformed source file, first argument of NewArchive must be a CompositeLit")
-        }
 	archiveFiles := make(map[string]bog.File)
 	for _, elt := range firstArg.Elts {
 		keyValExpr, ok := elt.(*ast.KeyValueExpr)
 		if !ok {
-			return nil, errors.New("malformed source file, key value invalid")
-                }
-		key, ok := keyValExpr.Key.(*ast.BasicLit)
-                if !ok {
-                        return nil, errors.New("malformed source file, key value invalid")
-                }
-		val, ok := keyValExpr.Value.(*ast.Ident)
-                if !ok {
-                        return nil, errors.New("malformed source file, key value invalid")
-                }
-		f, ok := files[val.Name]
-		if !ok {
-			return nil, errors.New("malformed source file, file not found: " + val.Name)
-		}
-		archiveFiles[strings.Trim(key.Value, "\"")] = f
-	}
-	var isFile bool
-	thirdArgs, ok := args[2].(*ast.Ident)
-        if !ok {
-                return nil, errors.New("malformed source file, third argument of NewArchive must be an Ident")
-        }
-        if thirdArgs.Name == "true" {
-		isFile = true
-        } else {
-		isFile = false
-	}
-	fourthArg, ok := args[3].(*ast.BasicLit)
-        if !ok {
-                return nil, errors.New("malformed source file, fourth argument of NewArchive must be a BasicLit")
-        }
-	return bog.NewArchive(archiveFiles, false, isFile, strings.Trim(fourthArg.Value, "\"")), nil
-}
-
-func parseStat(arg ast.Expr) (*bog.FileInfo, error) {
-	var fileName string
-	var fileMode os.FileMode
-	secondArg, ok := arg.(*ast.UnaryExpr)
+			return nil, l.errorf(elt, "malformed source file, key value invalid")
+		}
+		key, err := l.parseString(keyValExpr.Key)
+		if err != nil {
+			return nil, err
+		}
+		f, err := l.file(keyValExpr.Value)
+		if err != nil {
+			return nil, err
+		}
+		archiveFiles[key] = f
+	}
+	isFile, err := l.parseBool(call.Args[2])
+	if err != nil {
+		return nil, err
+	}
+	root, err := l.parseString(call.Args[3])
+	if err != nil {
+		return nil, err
+	}
+	return bog.NewArchive(archiveFiles, false, isFile, root), nil
+}
+
+// createFSArchive rebuilds an archive generated with -embed from the table of its files, reading the
+// embedded files from folder, the folder of the generated file.
+func (l *loader) createFSArchive(call *ast.CallExpr, folder string) (*bog.Archive, error) {
+	if len(call.Args) != 6 {
+		return nil, l.errorf(call, "malformed source file, NewFSArchive has exactly 6 arguments")
+	}
+	dir, err := l.parseString(call.Args[1])
+	if err != nil {
+		return nil, err
+	}
+	dev, err := l.parseBool(call.Args[3])
+	if err != nil {
+		return nil, err
+	}
+	if dev {
+		return nil, l.errorf(call.Args[3], "cannot extract source file in development mode")
+	}
+	infosArg, ok := call.Args[2].(*ast.CompositeLit)
 	if !ok {
-		return nil, errors.New("malformed source file, second argument of NewBogFile must be a UnaryExpr")
+		return nil, l.errorf(call.Args[2], "malformed source file, third argument of NewFSArchive must be a CompositeLit")
+	}
+	infos := make(map[string]*bog.FileInfo)
+	for _, elt := range infosArg.Elts {
+		keyValExpr, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, l.errorf(elt, "malformed source file, key value invalid")
+		}
+		key, err := l.parseString(keyValExpr.Key)
+		if err != nil {
+			return nil, err
+		}
+		infos[key], err = l.parseStat(keyValExpr.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	isFile, err := l.parseBool(call.Args[4])
+	if err != nil {
+		return nil, err
+	}
+	root, err := l.parseString(call.Args[5])
+	if err != nil {
+		return nil, err
 	}
-	x, ok := secondArg.X.(*ast.CompositeLit)
+	return bog.NewFSArchive(os.DirFS(folder), dir, infos, false, isFile, root), nil
+}
+
+// parseData parses file data, either a []byte composite literal or a []byte conversion of a string literal.
+func (l *loader) parseData(expr ast.Expr) ([]byte, error) {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		if x.Name == "nil" {
+			return nil, nil
+		}
+	case *ast.CompositeLit:
+		buffer := &bytes.Buffer{}
+		for _, elt := range x.Elts {
+			val, err := l.parseInt(elt)
+			if err != nil {
+				return nil, err
+			}
+			if val < 0 || val > 255 {
+				return nil, l.errorf(elt, "malformed source file, byte value out of range")
+			}
+			buffer.WriteByte(byte(val))
+		}
+		return buffer.Bytes(), nil
+	case *ast.CallExpr:
+		if len(x.Args) == 1 {
+			s, err := l.parseString(x.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return []byte(s), nil
+		}
+	}
+	return nil, l.errorf(expr, "malformed source file, data invalid")
+}
+
+func (l *loader) parseStat(arg ast.Expr) (*bog.FileInfo, error) {
+	stat := &bog.FileInfo{}
+	unaryExpr, ok := arg.(*ast.UnaryExpr)
+	if !ok || unaryExpr.Op != token.AND {
+		return nil, l.errorf(arg, "malformed source file, file info must be a pointer to bog.FileInfo")
+	}
+	x, ok := unaryExpr.X.(*ast.CompositeLit)
 	if !ok {
-		return nil, errors.New("malformed source file, X of second argument of NewBogFile must be a CompositeLit")
+		return nil, l.errorf(arg, "malformed source file, file info must be a pointer to bog.FileInfo")
 	}
 	for _, elt := range x.Elts {
 		keyValExpr, ok := elt.(*ast.KeyValueExpr)
 		if !ok {
-			return nil, errors.New("malformed source file, key value invalid")
+			return nil, l.errorf(elt, "malformed source file, key value invalid")
 		}
 		key, ok := keyValExpr.Key.(*ast.Ident)
 		if !ok {
-			return nil, errors.New("malformed source file, key value invalid")
+			return nil, l.errorf(elt, "malformed source file, key value invalid")
 		}
-		val, ok := keyValExpr.Value.(*ast.BasicLit)
-		if !ok {
-			// must ignore here because of time.Unix call
-			continue
+		var err error
+		switch key.Name {
+		case "FileName":
+			stat.FileName, err = l.parseString(keyValExpr.Value)
+		case "FileSize":
+			stat.FileSize, err = l.parseInt(keyValExpr.Value)
+		case "FileMode":
+			var mode int64
+			mode, err = l.parseInt(keyValExpr.Value)
+			stat.FileMode = os.FileMode(mode)
+		case "FileModTime":
+			stat.FileModTime, err = l.parseTime(keyValExpr.Value)
+		case "FileHash":
+			stat.FileHash, err = l.parseString(keyValExpr.Value)
+		case "FileContentType":
+			stat.FileContentType, err = l.parseString(keyValExpr.Value)
 		}
-		if key.Name == "FileName" {
-			fileName = strings.Trim(val.Value, "\"")
+		if err != nil {
+			return nil, err
 		}
-		if key.Name == "FileMode" {
-			val, err := strconv.ParseInt(val.Value, 0, 64)
-			if err != nil {
-				return nil, err
-			}
-			fileMode = os.FileMode(val)
+	}
+	return stat, nil
+}
+
+func (l *loader) parseTime(expr ast.Expr) (time.Time, error) {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 2 || selectorName(callExpr.Fun, "time") != "Unix" {
+		return time.Time{}, l.errorf(expr, "malformed source file, modification time must be a time.Unix call")
+	}
+	sec, err := l.parseInt(callExpr.Args[0])
+	if err != nil {
+		return time.Time{}, err
+	}
+	nsec, err := l.parseInt(callExpr.Args[1])
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+func (l *loader) parseInt(expr ast.Expr) (int64, error) {
+	basicLit, ok := expr.(*ast.BasicLit)
+	if !ok {
+		return 0, l.errorf(expr, "malformed source file, integer literal expected")
+	}
+	switch basicLit.Kind {
+	case token.INT:
+		val, err := strconv.ParseInt(basicLit.Value, 0, 64)
+		if err != nil {
+			return 0, l.errorf(expr, "%v", err)
+		}
+		return val, nil
+	case token.CHAR:
+		val, _, _, err := strconv.UnquoteChar(basicLit.Value[1:len(basicLit.Value)-1], '\'')
+		if err != nil {
+			return 0, l.errorf(expr, "%v", err)
 		}
+		return int64(val), nil
+	}
+	return 0, l.errorf(expr, "malformed source file, integer literal expected")
+}
+
+// parseString parses a string literal, or a concatenation of string literals.
+func (l *loader) parseString(expr ast.Expr) (string, error) {
+	if binaryExpr, ok := expr.(*ast.BinaryExpr); ok && binaryExpr.Op == token.ADD {
+		x, err := l.parseString(binaryExpr.X)
+		if err != nil {
+			return "", err
+		}
+		y, err := l.parseString(binaryExpr.Y)
+		if err != nil {
+			return "", err
+		}
+		return x + y, nil
+	}
+	basicLit, ok := expr.(*ast.BasicLit)
+	if !ok || basicLit.Kind != token.STRING {
+		return "", l.errorf(expr, "malformed source file, string literal expected")
+	}
+	s, err := strconv.Unquote(basicLit.Value)
+	if err != nil {
+		return "", l.errorf(expr, "%v", err)
+	}
+	return s, nil
+}
+
+func (l *loader) parseBool(expr ast.Expr) (bool, error) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || (ident.Name != "true" && ident.Name != "false") {
+		return false, l.errorf(expr, "malformed source file, boolean expected")
 	}
-	return &bog.FileInfo{
-		FileName: fileName,
-		FileMode: fileMode,
-	}, nil
+	return ident.Name == "true", nil
 }
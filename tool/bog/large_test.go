@@ -0,0 +1,95 @@
+//go:build go1.19
+// +build go1.19
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+const (
+	largeTreeFiles    = 400
+	largeTreeFileSize = 1 << 20
+	// largeTreeMaxHeap bounds the heap while generating the 400MB tree with 4 jobs.
+	largeTreeMaxHeap = 128 << 20
+)
+
+// writeLargeTree writes a tree of text files of largeTreeFileSize bytes under dir.
+func writeLargeTree(t *testing.T, dir string) {
+	line := []byte("the quick brown fox jumps over the lazy dog 0123456789\n")
+	data := bytes.Repeat(line, largeTreeFileSize/len(line)+1)[:largeTreeFileSize]
+	for i := 0; i < largeTreeFiles; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("d%02d", i%20), fmt.Sprintf("f%03d.txt", i))
+		err := os.MkdirAll(filepath.Dir(name), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Files differ by their first bytes, so that they are not shared as duplicates.
+		copy(data, fmt.Sprintf("%08d", i))
+		err = os.WriteFile(name, data, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// heapSys runs f with a soft memory limit of limit bytes, and returns the heap memory obtained from
+// the system once f returns. The limit makes the collector keep the heap close to the live memory,
+// and the heap memory obtained from the system never shrinks, so it bounds the peak of the heap
+// without sampling it.
+func heapSys(limit int64, f func()) uint64 {
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(limit))
+	var stats runtime.MemStats
+	f()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapSys
+}
+
+// TestGenerateLargeTree checks that generating an archive of a large tree, from a folder or a tar
+// file, only holds the files being processed in memory. It writes several hundred MB, so it only
+// runs when BOG_LARGE_TEST environment variable is set.
+func TestGenerateLargeTree(t *testing.T) {
+	if os.Getenv("BOG_LARGE_TEST") == "" {
+		t.Skip("set BOG_LARGE_TEST=1 to write a tree of several hundred MB")
+	}
+	setenv(t, "BOGCACHE", "off")
+	jobs := Options.Jobs
+	Options.Jobs = 4
+	defer func() { Options.Jobs = jobs }()
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "assets")
+	writeLargeTree(t, source)
+	tarSource := filepath.Join(dir, "assets.tar")
+	writeTar(t, source, tarSource)
+
+	for _, src := range []string{source, tarSource} {
+		p := defaultParams(trimArchiveExt(src), "assets", true)
+		p.Source = src
+		p.Output = filepath.Join(dir, "assets-archive.go")
+		var err error
+		heap := heapSys(largeTreeMaxHeap/2, func() {
+			_, _, err = generate(p)
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		info, err := os.Stat(p.Output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() < largeTreeFiles*largeTreeFileSize {
+			t.Errorf("%s: generated file holds %d bytes, expected at least %d", src, info.Size(), largeTreeFiles*largeTreeFileSize)
+		}
+		t.Logf("%s: heap of %d MB", src, heap>>20)
+		if heap > largeTreeMaxHeap {
+			t.Errorf("%s: heap of %d MB, expected at most %d MB", src, heap>>20, largeTreeMaxHeap>>20)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/keimoon/bog"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// listEntry describes a file of an archive in the output of list command
+type listEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode"`
+	ModTime time.Time `json:"modTime"`
+	IsDir   bool      `json:"isDir"`
+	// ContentType is the MIME type recorded at generation time
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// List prints the files of an archived go source file
+func List() int {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "Print the files as JSON")
+	varName := flags.String("var", "", "Name of the archive variable, if the file contains several archives")
+	flags.Parse(Args[1:])
+	if flags.NArg() != 1 {
+		Usage()
+		return 2
+	}
+	archive, err := loadArchive(flags.Arg(0), *varName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	entries, err := archiveEntries(archive)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	err = printEntries(entries, *asJSON)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// archiveEntries describes every file of archive
+func archiveEntries(archive *bog.Archive) ([]*listEntry, error) {
+	entries := []*listEntry{}
+	err := archive.Walk("", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		entry := &listEntry{
+			Path:    path,
+			Size:    info.Size(),
+			Mode:    info.Mode().String(),
+			ModTime: info.ModTime(),
+			IsDir:   info.IsDir(),
+		}
+		if fileInfo, ok := info.(*bog.FileInfo); ok {
+			entry.ContentType = fileInfo.ContentType()
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// printEntries prints entries to stdout as a table or as JSON
+func printEntries(entries []*listEntry, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", entry.Mode, entry.Size, entry.ModTime.Format("2006-01-02 15:04:05"), entry.Path)
+	}
+	return w.Flush()
+}
+
+// Cat writes the content of a single file of an archived go source file to stdout
+func Cat() int {
+	flags := flag.NewFlagSet("cat", flag.ExitOnError)
+	varName := flags.String("var", "", "Name of the archive variable, if the file contains several archives")
+	flags.Parse(Args[1:])
+	if flags.NArg() != 2 {
+		Usage()
+		return 2
+	}
+	archive, err := loadArchive(flags.Arg(0), *varName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	f, err := archive.Open(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+	_, err = io.Copy(os.Stdout, f)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
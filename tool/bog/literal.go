@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"github.com/keimoon/bog"
+	"strconv"
+	"unicode/utf8"
+)
+
+// rawText reports whether data can be written as a raw string literal, keeping its lines: valid
+// UTF-8 text without backquotes, carriage returns, byte order marks or control characters other
+// than tabs and newlines.
+func rawText(data []byte) bool {
+	if len(data) == 0 || !utf8.Valid(data) || bytes.ContainsAny(data, "`\r\ufeff") {
+		return false
+	}
+	for _, c := range data {
+		if c < ' ' && c != '\t' && c != '\n' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// FrameLiteral returns the Go expression of the frame of the file. The data of raw text files is
+// written as a raw string literal between the quoted header and the quoted compressed variant, so
+// that every line of the file is a line of the generated file.
+func (f *FileVar) FrameLiteral() string {
+	if !f.Raw {
+		return strconv.Quote(string(f.Frame))
+	}
+	_, _, end, err := bog.ReadFrame(f.Frame)
+	if err != nil {
+		return strconv.Quote(string(f.Frame))
+	}
+	start := end - len(f.Data)
+	literal := strconv.Quote(string(f.Frame[:start])) + " + `" + string(f.Frame[start:end]) + "`"
+	if end < len(f.Frame) {
+		literal += " + " + strconv.Quote(string(f.Frame[end:]))
+	}
+	return literal
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRawText(t *testing.T) {
+	tests := map[string]bool{
+		"":                  false,
+		"line 1\nline 2\n":  true,
+		"\tindented\n":      true,
+		"héllo, 世界\n":       true,
+		"a `quoted` word\n": false,
+		"windows\r\n":       false,
+		"\ufeffwith BOM\n":  false,
+		"bell\a\n":          false,
+		"delete\x7f\n":      false,
+		"invalid \xff utf8": false,
+		"nul\x00":           false,
+	}
+	for data, expected := range tests {
+		if got := rawText([]byte(data)); got != expected {
+			t.Errorf("rawText(%q) = %v, expected %v", data, got, expected)
+		}
+	}
+}
+
+func TestGenerateRaw(t *testing.T) {
+	page := strings.Repeat("<p>a line of the page</p>\n", 20)
+	_, _, generated := generateAndLoad(t, map[string]string{
+		"page.html":  page,
+		"single.txt": "no newline",
+		"crlf.txt":   "windows\r\n",
+		"quote.txt":  "a `quoted` word\n",
+	}, func(p *params) {
+		p.Raw = true
+		p.Gzip = true
+	})
+	// Every line of raw text files is a line of the generated file.
+	if !strings.Contains(generated, "\n<p>a line of the page</p>\n<p>a line of the page</p>\n") {
+		t.Errorf("page.html is not written as a raw string literal")
+	}
+	if !strings.Contains(generated, " + `no newline`") {
+		t.Errorf("single.txt is not written as a raw string literal")
+	}
+	for _, quoted := range []string{`windows\r\n`, "a `quoted` word\\n"} {
+		if !strings.Contains(generated, quoted) {
+			t.Errorf("%q is not written as a quoted string literal", quoted)
+		}
+	}
+}
@@ -5,12 +5,23 @@ import (
 	"os"
 	"path/filepath"
 	"flag"
+	"runtime"
 )
 
 // Options for archive command
 var Options = &struct {
 	PackageName string
 	Dev         bool
+	Ignore      ignoreFile
+	Fingerprint bool
+	Gzip        bool
+	Validate    bool
+	Checks      checkFlags
+	Transforms  transformFlags
+	Jobs        int
+	SplitSize   sizeFlag
+	Raw         bool
+	Embed       bool
 	isCwd       bool
 }{
 	isCwd: true,
@@ -23,9 +34,16 @@ var Args = []string{}
 // Usage for flag
 func Usage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "%s [flags] (archive|a) folder\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "%s [flags] (archive|a) (folder|file|file.tar|file.tar.gz|file.zip|-)\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "%s (archive|a)    build the archives described by bog.json\n", os.Args[0])
 	flag.PrintDefaults()
-	fmt.Fprintf(os.Stderr, "%s [flags] (extract|e) file.go\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "%s [flags] (extract|e) [-var name] [-format dir|tar|zip] [-o output] file.go\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "%s extract-binary [-list] [-json] [-var name] [-format dir|tar|zip] [-o output] executable\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "%s regen [-check] [packages]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "%s [flags] watch [-interval duration] [-debounce duration] [folder|file]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "%s [flags] serve [-addr :8080] [-var name] [-fallback file] [-404 file] [-list] [-hide-dotfiles] (folder|file|file.tar|file.zip|file.go)\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "%s list [-json] [-var name] file.go\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "%s cat [-var name] file.go /path/to/file\n", os.Args[0])
 }
 
 func main() {
@@ -39,6 +57,16 @@ func main() {
 	flag.StringVar(&Options.PackageName, "p", cwd, "Change package name")
 	
 	flag.BoolVar(&Options.Dev, "d", false, "Enable development mode")
+	flag.BoolVar(&Options.Fingerprint, "f", false, "Write a manifest of fingerprinted names")
+	flag.BoolVar(&Options.Gzip, "z", false, "Store a gzip compressed variant of compressible files")
+	flag.Var(&Options.Ignore, "i", "Read additional ignore rules from a file in .bogignore format")
+	flag.BoolVar(&Options.Validate, "validate", false, "Check the template syntax of .tmpl and .html files, and JSON and XML files before archiving")
+	flag.Var(&Options.Checks, "c", "Check files matched by glob with a shell command reading them from stdin, as glob=command")
+	flag.Var(&Options.Transforms, "t", "Transform files matched by glob with minify-css, minify-json, normalize-eol or a shell command filtering stdin, as glob=transform")
+	flag.IntVar(&Options.Jobs, "j", runtime.NumCPU(), "Number of files read and encoded in parallel")
+	flag.BoolVar(&Options.Embed, "embed", false, "Declare the archive over files embedded with //go:embed, instead of writing their data")
+	flag.BoolVar(&Options.Raw, "r", false, "Write text files as raw string literals, so that diffs of generated files show changed lines")
+	flag.Var(&Options.SplitSize, "split-size", "Move archived files to several generated files of about this size, like 50MB")
 	flag.Parse()
 	Args = flag.Args()
 	if len(Args) == 0 {
@@ -54,6 +82,18 @@ func main() {
 		os.Exit(Archive())
 	case "extract", "e":
 		os.Exit(Extract())
+	case "extract-binary":
+		os.Exit(ExtractBinary())
+	case "regen":
+		os.Exit(Regen())
+	case "watch":
+		os.Exit(Watch())
+	case "serve":
+		os.Exit(Serve())
+	case "list":
+		os.Exit(List())
+	case "cat":
+		os.Exit(Cat())
 	default:
 		Usage()
 	}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// mimeExts holds the MIME types of common web file extensions. They are preferred to the system
+// tables, which depend on the machine running the generator.
+var mimeExts = map[string]string{
+	".avif":        "image/avif",
+	".css":         "text/css; charset=utf-8",
+	".csv":         "text/csv; charset=utf-8",
+	".eot":         "application/vnd.ms-fontobject",
+	".gif":         "image/gif",
+	".gz":          "application/gzip",
+	".htm":         "text/html; charset=utf-8",
+	".html":        "text/html; charset=utf-8",
+	".ico":         "image/x-icon",
+	".jpeg":        "image/jpeg",
+	".jpg":         "image/jpeg",
+	".js":          "text/javascript; charset=utf-8",
+	".json":        "application/json",
+	".map":         "application/json",
+	".md":          "text/markdown; charset=utf-8",
+	".mjs":         "text/javascript; charset=utf-8",
+	".mp3":         "audio/mpeg",
+	".mp4":         "video/mp4",
+	".ogg":         "audio/ogg",
+	".otf":         "font/otf",
+	".pdf":         "application/pdf",
+	".png":         "image/png",
+	".svg":         "image/svg+xml",
+	".toml":        "application/toml",
+	".ttf":         "font/ttf",
+	".txt":         "text/plain; charset=utf-8",
+	".wasm":        "application/wasm",
+	".wav":         "audio/wav",
+	".webm":        "video/webm",
+	".webmanifest": "application/manifest+json",
+	".webp":        "image/webp",
+	".woff":        "font/woff",
+	".woff2":       "font/woff2",
+	".xml":         "text/xml; charset=utf-8",
+	".yaml":        "application/yaml",
+	".yml":         "application/yaml",
+	".zip":         "application/zip",
+}
+
+// mimeTypes detects the MIME type of archived files. Overrides are looked up first, then
+// the built-in extension table, then the content is sniffed.
+type mimeTypes map[string]string
+
+// newMimeTypes reads the overrides of the .bogmime file of the source and of p.Mime.
+// A .bogmime file holds one extension and its MIME type per line. Lines starting with # are comments.
+func newMimeTypes(p *params, config sourceConfig) (mimeTypes, error) {
+	types := mimeTypes{}
+	if b, ok := config[".bogmime"]; ok {
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			fields := strings.Fields(text)
+			if len(fields) < 2 {
+				return nil, fmt.Errorf(".bogmime:%d: expected an extension followed by a MIME type", line)
+			}
+			types.add(fields[0], strings.Join(fields[1:], " "))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	for ext, ctype := range p.Mime {
+		types.add(ext, ctype)
+	}
+	return types, nil
+}
+
+func (m mimeTypes) add(ext string, ctype string) {
+	m["."+strings.ToLower(strings.TrimPrefix(ext, "."))] = ctype
+}
+
+// detect returns the MIME type of the file name holding data.
+func (m mimeTypes) detect(name string, data []byte) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ctype, ok := m[ext]; ok {
+		return ctype
+	}
+	if ctype, ok := mimeExts[ext]; ok {
+		return ctype
+	}
+	return http.DetectContentType(data)
+}
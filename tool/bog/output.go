@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// outputWriter writes a generated file without holding its content in memory. The content is
+// compared with the existing file while being written, and nothing is written as long as they
+// are equal. The existing file is only replaced by commit, when they differ. In dry run mode,
+// the content is only compared.
+type outputWriter struct {
+	name   string
+	dryRun bool
+	// old reads the existing file, and n counts the bytes found equal in it.
+	old     *bufio.Reader
+	oldFile *os.File
+	n       int64
+	buf     []byte
+	differ  bool
+	tmp     *os.File
+	w       *bufio.Writer
+}
+
+func newOutputWriter(name string, dryRun bool) *outputWriter {
+	o := &outputWriter{name: name, dryRun: dryRun, buf: make([]byte, 32*1024)}
+	f, err := os.Open(name)
+	if err != nil {
+		o.differ = true
+		return o
+	}
+	o.oldFile = f
+	o.old = bufio.NewReader(f)
+	return o
+}
+
+func (o *outputWriter) Write(b []byte) (int, error) {
+	written := len(b)
+	for !o.differ && len(b) > 0 {
+		chunk := b
+		if len(chunk) > len(o.buf) {
+			chunk = chunk[:len(o.buf)]
+		}
+		n, _ := io.ReadFull(o.old, o.buf[:len(chunk)])
+		if n < len(chunk) || !bytes.Equal(o.buf[:n], chunk) {
+			err := o.diverge()
+			if err != nil {
+				return 0, err
+			}
+			break
+		}
+		o.n += int64(n)
+		b = b[n:]
+	}
+	if o.differ && !o.dryRun && len(b) > 0 {
+		if o.w == nil {
+			err := o.diverge()
+			if err != nil {
+				return 0, err
+			}
+		}
+		_, err := o.w.Write(b)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return written, nil
+}
+
+// diverge is called when the content differs from the existing file for the first time. The temporary
+// file replacing it starts with the bytes found equal.
+func (o *outputWriter) diverge() error {
+	o.differ = true
+	if o.dryRun {
+		return nil
+	}
+	dir := filepath.Dir(o.name)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	o.tmp, err = ioutil.TempFile(dir, "."+filepath.Base(o.name)+".tmp")
+	if err != nil {
+		return err
+	}
+	o.w = bufio.NewWriter(o.tmp)
+	if o.oldFile != nil && o.n > 0 {
+		_, err = io.Copy(o.w, io.NewSectionReader(o.oldFile, 0, o.n))
+	}
+	return err
+}
+
+// commit replaces the existing file if the content differs. It reports whether they differ.
+func (o *outputWriter) commit() (bool, error) {
+	if !o.differ {
+		if _, err := o.old.ReadByte(); err != io.EOF {
+			if err := o.diverge(); err != nil {
+				o.abort()
+				return false, err
+			}
+		}
+	}
+	if o.oldFile != nil {
+		o.oldFile.Close()
+		o.oldFile = nil
+	}
+	if !o.differ || o.dryRun {
+		return o.differ, nil
+	}
+	if o.tmp == nil {
+		err := o.diverge()
+		if err != nil {
+			o.abort()
+			return false, err
+		}
+	}
+	err := o.w.Flush()
+	if err == nil {
+		err = o.tmp.Chmod(0644)
+	}
+	if closeErr := o.tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(o.tmp.Name(), o.name)
+	}
+	if err != nil {
+		os.Remove(o.tmp.Name())
+	}
+	o.tmp = nil
+	return true, err
+}
+
+// abort leaves the existing file untouched.
+func (o *outputWriter) abort() {
+	if o.oldFile != nil {
+		o.oldFile.Close()
+		o.oldFile = nil
+	}
+	if o.tmp != nil {
+		o.tmp.Close()
+		os.Remove(o.tmp.Name())
+		o.tmp = nil
+	}
+}
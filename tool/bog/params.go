@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// paramsPrefix starts the comment line recording generation parameters in generated files.
+const paramsPrefix = "//bog:params "
+
+// params holds everything needed to generate an archive. They are recorded in the header
+// of the generated file, so that "bog regen" can run the generation again.
+type params struct {
+	// Source is the archived folder or file. It is recorded relative to the generated file.
+	Source string `json:"source,omitempty"`
+	// Mounts replace Source to archive several sources. Their sources are recorded relative to the generated file.
+	Mounts []*mount `json:"mounts,omitempty"`
+	// Root is the root folder passed to bog.NewArchive.
+	Root    string `json:"root"`
+	Package string `json:"package"`
+	Var     string `json:"var"`
+	Dev     bool   `json:"dev,omitempty"`
+	// Ignore holds additional ignore rules, applied from the root folder.
+	Ignore []string `json:"ignore,omitempty"`
+	// Fingerprint writes a manifest of fingerprinted names next to the generated file.
+	Fingerprint bool `json:"fingerprint,omitempty"`
+	// Gzip stores a gzip compressed variant of compressible files.
+	Gzip bool `json:"gzip,omitempty"`
+	// Mime maps file extensions to MIME types, overriding the ones found in .bogmime and the detected ones.
+	Mime map[string]string `json:"mime,omitempty"`
+	// Validate checks the template syntax of templates, and JSON and XML files before archiving them.
+	Validate bool `json:"validate,omitempty"`
+	// Checks maps globs to shell commands checking the matched files, read from stdin.
+	Checks map[string]string `json:"checks,omitempty"`
+	// Transforms are applied in order to the content of matched files before archiving them.
+	Transforms []*transform `json:"transforms,omitempty"`
+	// Embed declares the archive over an embed.FS holding the files, instead of writing their data.
+	Embed bool `json:"embed,omitempty"`
+	// Raw writes text files as raw string literals, keeping their lines.
+	Raw bool `json:"raw,omitempty"`
+	// SplitSize moves the declarations of files to shards of about this size, next to the generated file.
+	SplitSize int64 `json:"splitSize,omitempty"`
+	// Output is the path of the generated file.
+	Output string `json:"-"`
+	// pkgPath is the import path of the generated package, recorded in the frames of files.
+	pkgPath string
+	// cache holds the fragments of the previous generation, if any.
+	cache *genCache
+	// sources holds the zip files and tar spools whose entries are read when files are loaded.
+	sources *sourceFiles
+	// dryRun keeps the cache untouched, when the generated files are not written.
+	dryRun bool
+}
+
+// newParams creates params for archiving source using command line options.
+func newParams(source string) *params {
+	root := source
+	if isArchiveSource(source) {
+		root = trimArchiveExt(source)
+		if source == stdinSource {
+			root = Options.PackageName
+		}
+	}
+	p := defaultParams(root, Options.PackageName, Options.isCwd)
+	p.Source = filepath.Clean(source)
+	p.Dev = Options.Dev
+	p.Ignore = []string(Options.Ignore)
+	p.Fingerprint = Options.Fingerprint
+	p.Gzip = Options.Gzip
+	p.Validate = Options.Validate
+	p.Checks = map[string]string(Options.Checks)
+	p.Transforms = []*transform(Options.Transforms)
+	p.Embed = Options.Embed
+	p.Raw = Options.Raw
+	p.SplitSize = int64(Options.SplitSize)
+	return p
+}
+
+// defaultParams returns params with the root folder, package, variable name and output derived
+// from root and packageName. The output is put in the folder of the package, unless the package
+// is main or the package of the current folder.
+func defaultParams(root string, packageName string, isCwd bool) *params {
+	filePackageName := makePackageName(root)
+	var outputFileName string
+	var varName string
+	if len(filePackageName) > 0 {
+		outputFileName = filePackageName + "-archive.go"
+		varName = makePublicVariableName(filePackageName) + "Archive"
+	} else {
+		outputFileName = packageName + "-archive.go"
+		varName = makePublicVariableName(packageName) + "Archive"
+	}
+	outputFolder := "."
+	if !isCwd && packageName != "main" {
+		outputFolder = packageName
+	}
+	return &params{
+		Root:    root,
+		Package: packageName,
+		Var:     varName,
+		Output:  filepath.Join(outputFolder, outputFileName),
+	}
+}
+
+// manifestName returns the path of the manifest of fingerprinted names.
+func (p *params) manifestName() string {
+	return strings.TrimSuffix(p.Output, ".go") + ".manifest.json"
+}
+
+// marshal returns the header line recording p.
+func (p *params) marshal() (string, error) {
+	recorded := *p
+	if p.Source == stdinSource {
+		b, err := json.Marshal(&recorded)
+		if err != nil {
+			return "", err
+		}
+		return paramsPrefix + string(b), nil
+	}
+	outputFolder, err := filepath.Abs(filepath.Dir(p.Output))
+	if err != nil {
+		return "", err
+	}
+	rel := func(source string) (string, error) {
+		source, err := filepath.Abs(source)
+		if err != nil {
+			return "", err
+		}
+		rel, err := filepath.Rel(outputFolder, source)
+		return filepath.ToSlash(rel), err
+	}
+	if len(p.Mounts) > 0 {
+		recorded.Mounts = nil
+		for _, m := range p.Mounts {
+			source, err := rel(m.Source)
+			if err != nil {
+				return "", err
+			}
+			recorded.Mounts = append(recorded.Mounts, &mount{Source: source, Path: m.Path})
+		}
+	} else {
+		recorded.Source, err = rel(p.Source)
+		if err != nil {
+			return "", err
+		}
+	}
+	b, err := json.Marshal(&recorded)
+	if err != nil {
+		return "", err
+	}
+	return paramsPrefix + string(b), nil
+}
+
+var errNoParams = errors.New("no generation parameters found")
+
+// readParams reads the generation parameters recorded in the generated file filename.
+// It returns errNoParams if the file was not generated by bog.
+func readParams(filename string) (*params, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		if !strings.HasPrefix(line, paramsPrefix) {
+			continue
+		}
+		p := &params{}
+		err = json.Unmarshal([]byte(strings.TrimPrefix(line, paramsPrefix)), p)
+		if err != nil {
+			return nil, errors.New(filename + ": malformed generation parameters: " + err.Error())
+		}
+		if p.Source == stdinSource {
+			return nil, errors.New(filename + ": generated from stdin, cannot be regenerated")
+		}
+		if p.Source != "" {
+			p.Source = filepath.Join(filepath.Dir(filename), filepath.FromSlash(p.Source))
+		}
+		for _, m := range p.Mounts {
+			if m.Source == stdinSource {
+				return nil, errors.New(filename + ": generated from stdin, cannot be regenerated")
+			}
+			m.Source = filepath.Join(filepath.Dir(filename), filepath.FromSlash(m.Source))
+		}
+		p.Output = filename
+		return p, nil
+	}
+	if err = scanner.Err(); err != nil && err != bufio.ErrTooLong {
+		return nil, err
+	}
+	return nil, errNoParams
+}
+
+// packagePath returns the import path of the package in folder dir, found from the module
+// declared by the closest go.mod, or else from GOPATH. It returns name when neither is found.
+func packagePath(dir string, name string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return name
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		if module := modulePath(filepath.Join(d, "go.mod")); module != "" {
+			rel, err := filepath.Rel(d, dir)
+			if err != nil {
+				return name
+			}
+			return path.Join(module, filepath.ToSlash(rel))
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		rel, err := filepath.Rel(filepath.Join(gopath, "src"), dir)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return name
+}
+
+// modulePath returns the module path declared by the go.mod file filename, or an empty
+// string if it cannot be read.
+func modulePath(filename string) string {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		if module, err := strconv.Unquote(fields[1]); err == nil {
+			return module
+		}
+		return fields[1]
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Regen regenerates bog generated files using the parameters recorded in them
+func Regen() int {
+	flags := flag.NewFlagSet("regen", flag.ExitOnError)
+	check := flags.Bool("check", false, "Report out of date files without writing them")
+	flags.Parse(Args[1:])
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	files, err := findGenerated(patterns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	status := 0
+	for _, filename := range files {
+		p, err := readParams(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+		p.dryRun = *check
+		written, _, err := generate(p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filename, err)
+			status = 1
+			continue
+		}
+		for _, name := range written {
+			fmt.Println(name)
+			if *check {
+				status = 1
+			}
+		}
+	}
+	return status
+}
+
+// findGenerated returns bog generated files matched by patterns. A pattern is either a Go file,
+// a folder, or a folder followed by "/..." to also search its sub folders.
+func findGenerated(patterns []string) ([]string, error) {
+	files := []string{}
+	for _, pattern := range patterns {
+		recursive := false
+		if pattern == "..." || strings.HasSuffix(pattern, "/...") {
+			recursive = true
+			pattern = strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+			if pattern == "" {
+				pattern = "."
+			}
+		}
+		stat, err := os.Stat(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if !stat.IsDir() {
+			files = append(files, pattern)
+			continue
+		}
+		err = filepath.Walk(pattern, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if path == pattern {
+					return nil
+				}
+				name := info.Name()
+				if !recursive || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+			_, err = readParams(path)
+			if err == errNoParams {
+				return nil
+			}
+			files = append(files, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"github.com/keimoon/bog"
+	"text/template"
+)
+
+// rendered holds the declaration of a file or folder variable, or the problems found in the file.
+type rendered struct {
+	fragment string
+	errs     validationError
+	err      error
+}
+
+// renderFiles reads and renders fileVars with jobs workers, and calls emit with every declaration in
+// the order of fileVars, so that the output does not depend on scheduling. At most jobs files are held
+// in memory. It stops at the first error returned by emit.
+func renderFiles(p *params, tmpl *template.Template, mimeTypes mimeTypes, fileVars []*FileVar, jobs int,
+	emit func(fileVar *FileVar, r *rendered) error) error {
+	if jobs < 1 {
+		jobs = 1
+	}
+	pending := make(chan chan *rendered, jobs-1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(pending)
+		for _, fileVar := range fileVars {
+			done := make(chan *rendered, 1)
+			select {
+			case pending <- done:
+			case <-stop:
+				return
+			}
+			go func(fileVar *FileVar) {
+				done <- render(p, tmpl, mimeTypes, fileVar)
+			}(fileVar)
+		}
+	}()
+	i := 0
+	for done := range pending {
+		err := emit(fileVars[i], <-done)
+		if err != nil {
+			return err
+		}
+		i++
+	}
+	return nil
+}
+
+// renderShared returns the declaration of fileVar sharing the data of fileVar.Shared. Its frame only
+// holds its header.
+func renderShared(p *params, tmpl *template.Template, fileVar *FileVar) (string, error) {
+	header := frameHeader(p, fileVar)
+	header.Shared = true
+	frame, err := bog.AppendFrame(nil, header, nil)
+	if err != nil {
+		return "", err
+	}
+	fileVar.Frame = frame
+	buf := &bytes.Buffer{}
+	err = tmpl.ExecuteTemplate(buf, "shared", fileVar)
+	fileVar.Frame = nil
+	return buf.String(), err
+}
+
+// render returns the declaration of fileVar, from the cache or by loading and rendering the file.
+func render(p *params, tmpl *template.Template, mimeTypes mimeTypes, fileVar *FileVar) *rendered {
+	if fileVar.Cached != nil {
+		fragment, ok, err := p.cache.hit(fileVar)
+		if err != nil || ok {
+			return &rendered{fragment: fragment, err: err}
+		}
+		fileVar.Cached = nil
+	}
+	errs, err := load(p, mimeTypes, fileVar)
+	if err != nil || len(errs) > 0 {
+		return &rendered{errs: errs, err: err}
+	}
+	name := "file"
+	if fileVar.IsDir {
+		name = "folder"
+	}
+	buf := &bytes.Buffer{}
+	err = tmpl.ExecuteTemplate(buf, name, fileVar)
+	// The content of the file is not needed anymore.
+	fileVar.Data = nil
+	fileVar.Frame = nil
+	return &rendered{fragment: buf.String(), err: err}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/keimoon/bog"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Serve serves a folder, file, tar or zip file, or the archive of a generated file over HTTP
+func Serve() int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "Address to listen on")
+	varName := flags.String("var", "", "Name of the archive variable, if the generated file contains several archives")
+	fallback := flags.String("fallback", "", "File served for missing paths without extension, such as /index.html")
+	notFound := flags.String("404", "", "File served with status 404 for missing files")
+	listDirs := flags.Bool("list", false, "List the content of folders without index.html file")
+	hideDotfiles := flags.Bool("hide-dotfiles", false, "Make files whose name starts with a dot unreachable")
+	flags.Parse(Args[1:])
+	if flags.NArg() != 1 {
+		Usage()
+		return 2
+	}
+	source := flags.Arg(0)
+	var archives *archiveSource
+	if strings.HasSuffix(source, ".go") {
+		archive, err := loadArchive(source, *varName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		archives = &archiveSource{archive: archive}
+	} else {
+		p := newParams(source)
+		p.Dev = false
+		p.Fingerprint = false
+		if p.Source == stdinSource {
+			fmt.Fprintln(os.Stderr, "cannot serve stdin")
+			return 2
+		}
+		archives = &archiveSource{params: p}
+		if _, err := archives.get(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		archive, err := archives.get()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Path == "/_bog" || strings.HasPrefix(r.URL.Path, "/_bog/") {
+			serveContents(w, r, archive)
+			return
+		}
+		server := bog.NewServer(archive)
+		server.Fallback = *fallback
+		server.NotFound = *notFound
+		server.ListDirs = *listDirs
+		server.HideDotfiles = *hideDotfiles
+		server.ServeHTTP(w, r)
+	}
+	fmt.Fprintf(os.Stderr, "serving %s on %s\n", source, *addr)
+	err := http.ListenAndServe(*addr, http.HandlerFunc(handler))
+	fmt.Fprintln(os.Stderr, err)
+	return 1
+}
+
+// archiveSource holds the archive served by Serve. The archive of a live source is built again
+// when its files change.
+type archiveSource struct {
+	params   *params
+	mu       sync.Mutex
+	snapshot string
+	archive  *bog.Archive
+}
+
+func (s *archiveSource) get() (*bog.Archive, error) {
+	if s.params == nil {
+		return s.archive, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := snapshot(s.params)
+	if s.archive != nil && snap == s.snapshot {
+		return s.archive, nil
+	}
+	fileVars, mimeTypes, isFile, err := collect(s.params)
+	if err != nil {
+		return nil, err
+	}
+	defer s.params.sources.close()
+	for _, fileVar := range fileVars {
+		errs, err := load(s.params, mimeTypes, fileVar)
+		if err != nil {
+			return nil, err
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+	}
+	s.archive = newFileVarsArchive(s.params, fileVars, isFile)
+	s.snapshot = snap
+	return s.archive, nil
+}
+
+// newFileVarsArchive creates the archive holding fileVars, as the generated file would.
+func newFileVarsArchive(p *params, fileVars []*FileVar, isFile bool) *bog.Archive {
+	files := make(map[string]bog.File)
+	byVarName := make(map[string]bog.File)
+	for _, fileVar := range fileVars {
+		info := &bog.FileInfo{
+			FileName:        fileVar.Stat.Name(),
+			FileSize:        fileVar.Stat.Size(),
+			FileMode:        fileVar.Stat.Mode(),
+			FileModTime:     fileVar.Stat.ModTime(),
+			FileHash:        fileVar.Hash,
+			FileContentType: fileVar.ContentType,
+		}
+		var f bog.File
+		if fileVar.IsDir {
+			children := []bog.File{}
+			for _, child := range fileVar.Children {
+				children = append(children, byVarName[child])
+			}
+			f = bog.NewBogFolder(children, info)
+		} else {
+			f = bog.NewFramedFile(string(fileVar.Frame), info)
+		}
+		files[fileVar.Path] = f
+		byVarName[fileVar.VarName] = f
+	}
+	return bog.NewArchive(files, false, isFile, p.Root)
+}
+
+// serveContents writes an HTML page listing the files of archive with their sizes and content types.
+func serveContents(w http.ResponseWriter, r *http.Request, archive *bog.Archive) {
+	entries, err := archiveEntries(archive)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var files, total int64
+	for _, entry := range entries {
+		if !entry.IsDir {
+			files++
+			total += entry.Size
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!doctype html>\n<title>bog</title>\n<p>%d files, %d bytes</p>\n<table>\n", files, total)
+	fmt.Fprintf(w, "<tr><th>Path</th><th>Size</th><th>Type</th></tr>\n")
+	for _, entry := range entries {
+		link := url.URL{Path: entry.Path}
+		size := fmt.Sprint(entry.Size)
+		if entry.IsDir {
+			size = "-"
+			link.Path = strings.TrimSuffix(link.Path, "/") + "/"
+		}
+		fmt.Fprintf(w, "<tr><td><a href=\"%s\">%s</a></td><td>%s</td><td>%s</td></tr>\n",
+			link.String(), html.EscapeString(entry.Path), size, html.EscapeString(entry.ContentType))
+	}
+	fmt.Fprintf(w, "</table>\n")
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"github.com/keimoon/bog"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// stdinSource is the source name used to read a tar stream from stdin
+const stdinSource = "-"
+
+var archiveExts = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+// isArchiveSource reports whether source is a tar or zip file, or stdin, instead of a folder or a file.
+func isArchiveSource(source string) bool {
+	return source == stdinSource || archiveExt(source) != ""
+}
+
+func archiveExt(source string) string {
+	lower := strings.ToLower(source)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(lower, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// trimArchiveExt removes the tar or zip extension of source, if any.
+func trimArchiveExt(source string) string {
+	return source[:len(source)-len(archiveExt(source))]
+}
+
+// collectSource returns the files and folders of the source of p, children first, and whether the source
+// is a single file. In development mode, files are not read, and no file is returned.
+func collectSource(p *params, names *varNames) ([]*FileVar, sourceConfig, bool, error) {
+	if isArchiveSource(p.Source) {
+		if p.Dev {
+			return nil, nil, false, errArchiveDev
+		}
+		fileVars, config, err := collectArchive(p, names)
+		return fileVars, config, false, err
+	}
+	stat, err := os.Stat(p.Source)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if p.Dev {
+		return nil, nil, !stat.IsDir(), nil
+	}
+	if stat.IsDir() {
+		fileVars, config, err := collectDir(p, names)
+		return fileVars, config, false, err
+	}
+	return collectFile(p, stat, names), nil, true, nil
+}
+
+// mount places the files of Source at Path in the archive.
+type mount struct {
+	Source string `json:"source"`
+	Path   string `json:"path"`
+}
+
+// collectMounts returns the files and folders of the sources mounted by p, children first.
+// Missing folders are created. Several sources may provide the same folder, but not the same file.
+func collectMounts(p *params, names *varNames) ([]*FileVar, sourceConfig, error) {
+	if p.Dev {
+		return nil, nil, errMountsDev
+	}
+	byPath := make(map[string]*FileVar)
+	entries := []*FileVar{}
+	config := sourceConfig{}
+	for _, m := range p.Mounts {
+		sub := *p
+		sub.Source = m.Source
+		sub.Mounts = nil
+		sub.cache = nil
+		fileVars, subConfig, isFile, err := collectSource(&sub, newVarNames())
+		if err != nil {
+			return nil, nil, err
+		}
+		for name, b := range subConfig {
+			config[name] = b
+		}
+		mountPath := path.Clean("/" + m.Path)
+		if isFile && mountPath == "/" {
+			return nil, nil, errors.New(m.Source + ": a file cannot be mounted at /")
+		}
+		for _, fileVar := range fileVars {
+			fileVar.Origin = sourceName(&sub, fileVar)
+			if fileVar.Path == "/" {
+				fileVar.Stat = &bog.FileInfo{
+					FileName:    path.Base(mountPath),
+					FileSize:    fileVar.Stat.Size(),
+					FileMode:    fileVar.Stat.Mode(),
+					FileModTime: fileVar.Stat.ModTime(),
+				}
+			}
+			fileVar.Path = path.Join(mountPath, fileVar.Path)
+			fileVar.VarName = ""
+			fileVar.Children = nil
+			if other, ok := byPath[fileVar.Path]; ok {
+				if other.IsDir && fileVar.IsDir {
+					continue
+				}
+				return nil, nil, errors.New(fileVar.Path + ": provided by both " + other.Origin + " and " + fileVar.Origin)
+			}
+			byPath[fileVar.Path] = fileVar
+			entries = append(entries, fileVar)
+		}
+	}
+	return linkFileVars(entries, p, names), config, nil
+}
+
+var errMountsDev = errors.New("development mode requires a single source")
+
+// configFiles are read from the root folder of the source to configure the generator. They are not archived,
+// unlike the files with the same name in other folders.
+var configFiles = []string{".bogmime"}
+
+func isConfigFile(name string) bool {
+	for _, configFile := range configFiles {
+		if name == configFile {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceConfig holds the content of the config files found in the root folder of the source, by name.
+type sourceConfig map[string][]byte
+
+// collectDir walks the source folder, and returns its files and folders, children first.
+// The content of files is not read.
+func collectDir(p *params, names *varNames) ([]*FileVar, sourceConfig, error) {
+	config := sourceConfig{}
+	for _, name := range configFiles {
+		b, err := ioutil.ReadFile(filepath.Join(p.Source, name))
+		if err == nil {
+			config[name] = b
+		} else if !os.IsNotExist(err) {
+			return nil, nil, err
+		}
+	}
+	p.cache.addConfig(config)
+	output, err := filepath.Abs(p.Output)
+	if err != nil {
+		return nil, nil, err
+	}
+	fileVars := []*FileVar{}
+	err = walk(p.Source, func(path string, info os.FileInfo, children []string) error {
+		rel, err := filepath.Rel(p.Source, path)
+		if err != nil {
+			return err
+		}
+		archivePath := "/"
+		if rel != "." {
+			archivePath += filepath.ToSlash(rel)
+		}
+		fileVar := &FileVar{
+			VarName: names.get(p.Var, archivePath),
+			Path:    archivePath,
+			Stat: &bog.FileInfo{
+				FileName:    info.Name(),
+				FileSize:    info.Size(),
+				FileMode:    info.Mode(),
+				FileModTime: info.ModTime(),
+			},
+		}
+		if info.IsDir() {
+			fileVar.IsDir = true
+			for _, child := range children {
+				fileVar.Children = append(fileVar.Children, names.get(p.Var, strings.TrimRight(archivePath, "/")+"/"+child))
+			}
+		} else {
+			fileVar.Source = info
+			fileVar.File = path
+			if entry, ok := p.cache.lookup(archivePath, info, fileVar.VarName); ok {
+				fileVar.Cached = entry
+			}
+		}
+		fileVars = append(fileVars, fileVar)
+		return nil
+	}, ignoreRules(p.Ignore), output)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fileVars, config, nil
+}
+
+// collectFile returns the single source file.
+func collectFile(p *params, stat os.FileInfo, names *varNames) []*FileVar {
+	return []*FileVar{{
+		VarName: names.get(p.Var, "/"+stat.Name()),
+		Path:    "/",
+		Stat:    stat,
+		File:    p.Source,
+	}}
+}
+
+// collectArchive reads the entries of a tar or zip source, and returns the
+// corresponding files and folders, children first.
+func collectArchive(p *params, names *varNames) ([]*FileVar, sourceConfig, error) {
+	var entries []*FileVar
+	var err error
+	switch {
+	case p.Source == stdinSource:
+		entries, err = readTar(os.Stdin, p.sources)
+	case archiveExt(p.Source) == ".zip":
+		entries, err = readZip(p.Source, p.sources)
+	default:
+		var f *os.File
+		f, err = os.Open(p.Source)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		entries, err = readTar(f, p.sources)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	config := sourceConfig{}
+	for _, entry := range entries {
+		if entry.IsDir {
+			continue
+		}
+		name := path.Base(entry.Path)
+		isConfig := path.Dir(entry.Path) == "/" && isConfigFile(name)
+		if !isConfig && name != ".bogignore" {
+			continue
+		}
+		entry.Data, err = entry.read()
+		if err != nil {
+			return nil, nil, err
+		}
+		if isConfig {
+			config[name] = entry.Data
+		}
+	}
+	return linkFileVars(filterIgnored(entries, ignoreRules(p.Ignore)), p, names), config, nil
+}
+
+// sourceFiles holds the zip files and tar spools of the sources, whose entries are read when files
+// are loaded, so that archive sources are not held in memory.
+type sourceFiles []io.Closer
+
+// close closes the files, and removes the tar spools.
+func (s *sourceFiles) close() {
+	if s == nil {
+		return
+	}
+	for _, c := range *s {
+		c.Close()
+	}
+	*s = nil
+}
+
+// tarSpool holds the data of the entries of a tar stream, which can only be read in order.
+type tarSpool struct {
+	*os.File
+}
+
+func (s *tarSpool) Close() error {
+	err := s.File.Close()
+	os.Remove(s.Name())
+	return err
+}
+
+// readTar reads the entries of a tar stream, which may be compressed with gzip. The data of files
+// is copied to a spool file added to sources, and read from there when files are loaded.
+func readTar(r io.Reader, sources *sourceFiles) ([]*FileVar, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	} else {
+		r = br
+	}
+	f, err := ioutil.TempFile("", "bog-tar")
+	if err != nil {
+		return nil, err
+	}
+	spool := &tarSpool{f}
+	*sources = append(*sources, spool)
+	w := bufio.NewWriter(spool)
+	var off int64
+	tr := tar.NewReader(r)
+	entries := []*FileVar{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries, w.Flush()
+		}
+		if err != nil {
+			return nil, err
+		}
+		info := header.FileInfo()
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			continue
+		}
+		entry := newEntry(header.Name, info)
+		if !entry.IsDir {
+			n, err := io.Copy(w, tr)
+			if err != nil {
+				return nil, err
+			}
+			start := off
+			entry.read = func() ([]byte, error) {
+				b := make([]byte, n)
+				_, err := spool.ReadAt(b, start)
+				return b, err
+			}
+			off += n
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// readZip reads the entries of a zip file. The zip file is added to sources, and the data of files
+// is read when they are loaded.
+func readZip(filename string, sources *sourceFiles) ([]*FileVar, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	*sources = append(*sources, zr)
+	entries := []*FileVar{}
+	for _, f := range zr.File {
+		info := f.FileInfo()
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			continue
+		}
+		entry := newEntry(f.Name, info)
+		if !entry.IsDir {
+			f := f
+			entry.read = func() ([]byte, error) {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return ioutil.ReadAll(rc)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func newEntry(name string, info os.FileInfo) *FileVar {
+	archivePath := path.Clean("/" + strings.Replace(name, "\\", "/", -1))
+	return &FileVar{
+		Path:  archivePath,
+		IsDir: info.IsDir(),
+		Stat: &bog.FileInfo{
+			FileName:    path.Base(archivePath),
+			FileSize:    info.Size(),
+			FileMode:    info.Mode(),
+			FileModTime: info.ModTime(),
+		},
+	}
+}
+
+// filterIgnored removes the entries ignored by rules and by the .bogignore files found among entries.
+// Like when walking a folder, the rules of a .bogignore file apply to the names below its folder.
+func filterIgnored(entries []*FileVar, rules ignoreRules) []*FileVar {
+	folderRules := make(map[string]ignoreRules)
+	for _, entry := range entries {
+		if !entry.IsDir && path.Base(entry.Path) == ".bogignore" {
+			dir := path.Dir(entry.Path)
+			scanner := bufio.NewScanner(bytes.NewReader(entry.Data))
+			for scanner.Scan() {
+				folderRules[dir] = append(folderRules[dir], scanner.Text())
+			}
+		}
+	}
+	kept := []*FileVar{}
+	for _, entry := range entries {
+		if entry.Path == "/" {
+			kept = append(kept, entry)
+			continue
+		}
+		ignored := false
+		entryRules := append(ignoreRules{}, rules...)
+		dir := "/"
+		for _, name := range strings.Split(strings.TrimPrefix(entry.Path, "/"), "/") {
+			entryRules = append(entryRules, folderRules[dir]...)
+			if entryRules.ignore(name) || dir == "/" && isConfigFile(name) {
+				ignored = true
+				break
+			}
+			dir = path.Join(dir, name)
+		}
+		if !ignored {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// linkFileVars names the entries and fills the children of folders. Missing folders are created.
+// Entries are returned children first, children being sorted by name, as when walking a folder.
+func linkFileVars(entries []*FileVar, p *params, names *varNames) []*FileVar {
+	byPath := make(map[string]*FileVar)
+	for _, entry := range entries {
+		byPath[entry.Path] = entry
+	}
+	children := make(map[string][]string)
+	var addFolder func(name string)
+	addFolder = func(name string) {
+		if _, ok := children[name]; ok {
+			return
+		}
+		children[name] = []string{}
+		if _, ok := byPath[name]; !ok {
+			folderName := path.Base(name)
+			if name == "/" {
+				folderName = path.Base(p.Root)
+			}
+			byPath[name] = &FileVar{
+				Path:  name,
+				IsDir: true,
+				Stat: &bog.FileInfo{
+					FileName:    folderName,
+					FileMode:    os.ModeDir | 0755,
+					FileModTime: time.Unix(0, 0),
+				},
+			}
+		}
+		if name != "/" {
+			dir := path.Dir(name)
+			addFolder(dir)
+			children[dir] = append(children[dir], name)
+		}
+	}
+	addFolder("/")
+	if info, ok := byPath["/"].Stat.(*bog.FileInfo); ok {
+		info.FileName = path.Base(p.Root)
+	}
+	for name, entry := range byPath {
+		if name == "/" {
+			continue
+		}
+		if entry.IsDir {
+			addFolder(name)
+		} else {
+			dir := path.Dir(name)
+			addFolder(dir)
+			children[dir] = append(children[dir], name)
+		}
+	}
+	fileVars := []*FileVar{}
+	var link func(name string)
+	link = func(name string) {
+		fileVar := byPath[name]
+		fileVar.VarName = names.get(p.Var, name)
+		if fileVar.IsDir {
+			sort.Strings(children[name])
+			for _, child := range children[name] {
+				link(child)
+				fileVar.Children = append(fileVar.Children, byPath[child].VarName)
+			}
+		}
+		fileVars = append(fileVars, fileVar)
+	}
+	link("/")
+	return fileVars
+}
+
+var errArchiveDev = errors.New("development mode requires a folder or a file, not a tar or zip file")
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeFiles writes files, keyed by slash separated paths, under dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(filename), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filename, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// collectPaths returns the sorted paths of the files, without folders, collected from source.
+func collectPaths(t *testing.T, source string) ([]string, mimeTypes) {
+	p := defaultParams(trimArchiveExt(source), "assets", true)
+	p.Source = source
+	fileVars, mimeTypes, _, err := collect(p)
+	if err != nil {
+		t.Fatalf("%s: %v", source, err)
+	}
+	defer p.sources.close()
+	paths := []string{}
+	for _, fileVar := range fileVars {
+		if !fileVar.IsDir {
+			paths = append(paths, fileVar.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, mimeTypes
+}
+
+func TestCollectConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "assets")
+	writeFiles(t, source, map[string]string{
+		".bogmime":     ".glsl text/x-glsl\n",
+		".bogignore":   "*.tmp\n",
+		"a.glsl":       "void main() {}\n",
+		"b.tmp":        "ignored\n",
+		"sub/.bogmime": ".glsl text/plain\n",
+		"sub/c.txt":    "c\n",
+	})
+	tarSource := filepath.Join(dir, "assets.tar")
+	writeTar(t, source, tarSource)
+
+	// Only the config files of the root folder are read and left out.
+	expected := []string{"/a.glsl", "/sub/.bogmime", "/sub/c.txt"}
+	for _, src := range []string{source, tarSource} {
+		paths, mimeTypes := collectPaths(t, src)
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("%s: collected %v, expected %v", src, paths, expected)
+		}
+		if mimeType := mimeTypes.detect("/sub/d.glsl", nil); mimeType != "text/x-glsl" {
+			t.Errorf("%s: .glsl files have type %q, expected the one of the root .bogmime", src, mimeType)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// shardPrefix starts the comment line naming the generated file a shard belongs to.
+const shardPrefix = "//bog:shard "
+
+var shardRegex = regexp.MustCompile(`^[0-9]{3,}\.go$`)
+
+// shardName returns the name of the n-th shard of the generated file output, counting from 1.
+func shardName(output string, n int) string {
+	return fmt.Sprintf("%s_%03d.go", strings.TrimSuffix(output, ".go"), n)
+}
+
+// shardNames returns the sorted names of the existing shards of the generated file output.
+func shardNames(output string) ([]string, error) {
+	dir := filepath.Dir(output)
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(filepath.Base(output), ".go") + "_"
+	names := []string{}
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasPrefix(name, prefix) || !shardRegex.MatchString(strings.TrimPrefix(name, prefix)) {
+			continue
+		}
+		filename := filepath.Join(dir, name)
+		if shardIndex(filename) == filepath.Join(dir, filepath.Base(output)) {
+			names = append(names, filename)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// shardIndex returns the name of the generated file the shard filename belongs to, or an empty
+// string if filename is not a shard.
+func shardIndex(filename string) string {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		if strings.HasPrefix(line, shardPrefix) {
+			return filepath.Join(filepath.Dir(filename), strings.TrimPrefix(line, shardPrefix))
+		}
+	}
+	return ""
+}
+
+// shardWriter writes the declarations of files to the shards of a generated file, starting a new
+// shard when the current one would grow over the split size.
+type shardWriter struct {
+	p    *params
+	tmpl *template.Template
+	n    int
+	size int64
+	out  *outputWriter
+	w    *bufio.Writer
+	// written holds the names of the shards whose content changed.
+	written []string
+}
+
+func (s *shardWriter) write(fragment string) error {
+	if s.w == nil || s.size > 0 && s.size+int64(len(fragment)) > s.p.SplitSize {
+		err := s.close()
+		if err != nil {
+			return err
+		}
+		s.n++
+		s.size = 0
+		s.out = newOutputWriter(shardName(s.p.Output, s.n), s.p.dryRun)
+		s.w = bufio.NewWriter(s.out)
+		err = s.tmpl.ExecuteTemplate(s.w, "shard", &struct {
+			PackageName string
+			Index       string
+		}{
+			PackageName: s.p.Package,
+			Index:       filepath.Base(s.p.Output),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err := s.w.WriteString(fragment)
+	s.size += int64(len(fragment))
+	return err
+}
+
+// close writes the current shard, if any.
+func (s *shardWriter) close() error {
+	if s.w == nil {
+		return nil
+	}
+	err := s.w.Flush()
+	if err != nil {
+		s.abort()
+		return err
+	}
+	changed, err := s.out.commit()
+	s.out, s.w = nil, nil
+	if changed {
+		s.written = append(s.written, shardName(s.p.Output, s.n))
+	}
+	return err
+}
+
+// abort leaves the current shard untouched.
+func (s *shardWriter) abort() {
+	if s.out != nil {
+		s.out.abort()
+		s.out, s.w = nil, nil
+	}
+}
+
+// removeStale removes the shards left by a previous generation, and returns their names. In dry
+// run mode, they are only returned.
+func (s *shardWriter) removeStale() ([]string, error) {
+	names, err := shardNames(s.p.Output)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]bool)
+	for n := 1; n <= s.n; n++ {
+		current[shardName(s.p.Output, n)] = true
+	}
+	removed := []string{}
+	for _, name := range names {
+		if current[name] {
+			continue
+		}
+		if !s.p.dryRun {
+			err = os.Remove(name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
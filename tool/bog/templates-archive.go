@@ -1,3 +1,6 @@
+// Code generated by bog. DO NOT EDIT.
+//bog:params {"source":"templates","root":"templates","package":"main","var":"TemplatesArchive"}
+
 package main
 
 import (
@@ -7,27 +10,29 @@ import (
 
 
 
-var vvv1413281467TemplatesMainGoTmpl = bog.NewBogFile([]byte{0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x20, 0x7b, 0x7b, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0xa, 0xa, 0x69, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x20, 0x28, 0xa, 0x9, 0x22, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x65, 0x69, 0x6d, 0x6f, 0x6f, 0x6e, 0x2f, 0x62, 0x6f, 0x67, 0x22, 0xa, 0x9, 0x7b, 0x7b, 0x69, 0x66, 0x20, 0x6e, 0x6f, 0x74, 0x20, 0x2e, 0x44, 0x65, 0x76, 0x7d, 0x7d, 0x22, 0x74, 0x69, 0x6d, 0x65, 0x22, 0x7b, 0x7b, 0x65, 0x6e, 0x64, 0x7d, 0x7d, 0xa, 0x29, 0xa, 0xa, 0x7b, 0x7b, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x69, 0x66, 0x20, 0x2e, 0x49, 0x73, 0x44, 0x69, 0x72, 0x7d, 0x7d, 0xa, 0x76, 0x61, 0x72, 0x20, 0x7b, 0x7b, 0x2e, 0x56, 0x61, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x20, 0x3d, 0x20, 0x62, 0x6f, 0x67, 0x2e, 0x4e, 0x65, 0x77, 0x42, 0x6f, 0x67, 0x46, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x28, 0x5b, 0x5d, 0x62, 0x6f, 0x67, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x7b, 0x7b, 0x22, 0x7b, 0x22, 0x7d, 0x7d, 0x7b, 0x7b, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x7d, 0x7d, 0x7b, 0x7b, 0x2e, 0x7d, 0x7d, 0x2c, 0x7b, 0x7b, 0x65, 0x6e, 0x64, 0x7d, 0x7d, 0x7b, 0x7b, 0x22, 0x7d, 0x22, 0x7d, 0x7d, 0x2c, 0x20, 0x26, 0x62, 0x6f, 0x67, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x7b, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x3a, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x2c, 0xa, 0x9, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x3a, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x2e, 0x53, 0x69, 0x7a, 0x65, 0x7d, 0x7d, 0x2c, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x3a, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x7d, 0x7d, 0x2c, 0xa, 0x9, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x3a, 0x74, 0x69, 0x6d, 0x65, 0x2e, 0x55, 0x6e, 0x69, 0x78, 0x28, 0x7b, 0x7b, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x2e, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x2e, 0x55, 0x6e, 0x69, 0x78, 0x7d, 0x7d, 0x2c, 0x20, 0x30, 0x29, 0x2c, 0xa, 0x7d, 0x29, 0xa, 0x7b, 0x7b, 0x65, 0x6c, 0x73, 0x65, 0x7d, 0x7d, 0xa, 0x76, 0x61, 0x72, 0x20, 0x7b, 0x7b, 0x2e, 0x56, 0x61, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x20, 0x3d, 0x20, 0x62, 0x6f, 0x67, 0x2e, 0x4e, 0x65, 0x77, 0x42, 0x6f, 0x67, 0x46, 0x69, 0x6c, 0x65, 0x28, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x7d, 0x7d, 0x2c, 0x20, 0x26, 0x62, 0x6f, 0x67, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x7b, 0xa, 0x9, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x3a, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x2c, 0x20, 0xa, 0x9, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x3a, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x2e, 0x53, 0x69, 0x7a, 0x65, 0x7d, 0x7d, 0x2c, 0x20, 0xa, 0x9, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x3a, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x7d, 0x7d, 0x2c, 0x20, 0xa, 0x9, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x3a, 0x74, 0x69, 0x6d, 0x65, 0x2e, 0x55, 0x6e, 0x69, 0x78, 0x28, 0x7b, 0x7b, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x2e, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x2e, 0x55, 0x6e, 0x69, 0x78, 0x7d, 0x7d, 0x2c, 0x20, 0x30, 0x29, 0x2c, 0xa, 0x7d, 0x29, 0xa, 0x7b, 0x7b, 0x65, 0x6e, 0x64, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x65, 0x6e, 0x64, 0x7d, 0x7d, 0xa, 0xa, 0x2f, 0x2f, 0x20, 0x7b, 0x7b, 0x2e, 0x56, 0x61, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x20, 0x69, 0x73, 0x20, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x64, 0x20, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x20, 0x66, 0x6f, 0x72, 0x20, 0x27, 0x7b, 0x7b, 0x2e, 0x52, 0x6f, 0x6f, 0x74, 0x7d, 0x7d, 0x27, 0xa, 0x76, 0x61, 0x72, 0x20, 0x7b, 0x7b, 0x2e, 0x56, 0x61, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x20, 0x3d, 0x20, 0x62, 0x6f, 0x67, 0x2e, 0x4e, 0x65, 0x77, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x28, 0x6d, 0x61, 0x70, 0x5b, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5d, 0x62, 0x6f, 0x67, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x7b, 0xa, 0x9, 0x7b, 0x7b, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x7d, 0x7d, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x50, 0x61, 0x74, 0x68, 0x7d, 0x7d, 0x3a, 0x20, 0x7b, 0x7b, 0x2e, 0x56, 0x61, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x2c, 0xa, 0x9, 0x7b, 0x7b, 0x65, 0x6e, 0x64, 0x7d, 0x7d, 0xa, 0x7d, 0x2c, 0x20, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x44, 0x65, 0x76, 0x7d, 0x7d, 0x2c, 0x20, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x49, 0x73, 0x46, 0x69, 0x6c, 0x65, 0x7d, 0x7d, 0x2c, 0x20, 0x7b, 0x7b, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x66, 0x20, 0x22, 0x25, 0x23, 0x76, 0x22, 0x20, 0x2e, 0x52, 0x6f, 0x6f, 0x74, 0x7d, 0x7d, 0x29, 0xa}, &bog.FileInfo{
+var vvvTemplatesarchiveMainGoTmpl = bog.NewFramedFile("\x00BOGFRM\x01\x1e\x01\x00\x00\xe3\t\x00\x00\x00\x00\x00\x00{\"archive\":\"TemplatesArchive\",\"package\":\"github.com/keimoon/bog/tool/bog\",\"root\":\"templates\",\"path\":\"/main.go.tmpl\",\"name\":\"main.go.tmpl\",\"mode\":420,\"modTime\":1792425553,\"hash\":\"4cd5c146c5bd4f26a5520684730cdc45eb0522cc83bcdee4fd882ee25f22e964\",\"contentType\":\"text/plain; charset=utf-8\"}{{define \"header\"}}// Code generated by bog. DO NOT EDIT.\n{{.Params}}\n\npackage {{.PackageName}}\n\nimport (\n\t\"github.com/keimoon/bog\"\n\t{{if not .Dev}}\"time\"{{end}}\n)\n\n{{end}}{{define \"footer\"}}\n\n// {{.VarName}} is archived variable for '{{.Root}}'\nvar {{.VarName}} = bog.NewArchive(map[string]bog.File{\n\t{{range .Files}}{{printf \"%#v\" .Path}}: {{.VarName}},\n\t{{end}}\n}, {{printf \"%#v\" .Dev}}, {{printf \"%#v\" .IsFile}}, {{printf \"%#v\" .Root}})\n{{end}}{{define \"folder\"}}\n\nvar {{.VarName}} = bog.NewBogFolder([]bog.File{{\"{\"}}{{range .Children}}{{.}},{{end}}{{\"}\"}}, &bog.FileInfo{\n        FileName:{{printf \"%#v\" .Stat.Name}},\n\tFileSize:{{printf \"%#v\" .Stat.Size}},\n        FileMode:{{printf \"%#v\" .Stat.Mode}},\n\tFileModTime:time.Unix({{.Stat.ModTime.Unix}}, 0),\n})\n\n{{end}}{{define \"file\"}}\n\nvar {{.VarName}} = bog.NewFramedFile({{.FrameLiteral}}, &bog.FileInfo{\n\tFileName:{{printf \"%#v\" .Stat.Name}}, \n\tFileSize:{{printf \"%#v\" .Stat.Size}}, \n\tFileMode:{{printf \"%#v\" .Stat.Mode}}, \n\tFileModTime:time.Unix({{.Stat.ModTime.Unix}}, 0),\n\tFileHash:{{printf \"%q\" .Hash}},\n\tFileContentType:{{printf \"%q\" .ContentType}},\n})\n\n{{end}}{{define \"shard\"}}// Code generated by bog. DO NOT EDIT.\n//bog:shard {{.Index}}\n\npackage {{.PackageName}}\n\nimport (\n\t\"github.com/keimoon/bog\"\n\t\"time\"\n)\n\n{{end}}{{define \"shared\"}}\n\nvar {{.VarName}} = bog.NewSharedFile({{printf \"%q\" .Frame}}, {{.Shared}}, &bog.FileInfo{\n\tFileName:{{printf \"%#v\" .Stat.Name}}, \n\tFileSize:{{printf \"%#v\" .Stat.Size}}, \n\tFileMode:{{printf \"%#v\" .Stat.Mode}}, \n\tFileModTime:time.Unix({{.Stat.ModTime.Unix}}, 0),\n\tFileHash:{{printf \"%q\" .Hash}},\n\tFileContentType:{{printf \"%q\" .ContentType}},\n})\n\n{{end}}{{define \"embed\"}}// Code generated by bog. DO NOT EDIT.\n{{.Params}}\n\npackage {{.PackageName}}\n\nimport (\n{{if not .Dev}}\t\"embed\"\n\t\"time\"\n\n{{end}}\t\"github.com/keimoon/bog\"\n)\n{{if not .Dev}}\n{{range .Patterns}}//go:embed {{.}}\n{{end}}var {{.FSVar}} embed.FS\n{{end}}\n// {{.VarName}} is archived variable for '{{.Root}}'\nvar {{.VarName}} = bog.NewFSArchive({{if .Dev}}nil{{else}}{{.FSVar}}{{end}}, {{printf \"%#v\" .Dir}}, {{if .Dev}}nil{{else}}map[string]*bog.FileInfo{\n{{range .Files}}\t{{printf \"%#v\" .Path}}: &bog.FileInfo{\n\t\tFileName:{{printf \"%#v\" .Stat.Name}},\n\t\tFileSize:{{printf \"%#v\" .Stat.Size}},\n\t\tFileMode:{{printf \"%#v\" .Stat.Mode}},\n\t\tFileModTime:time.Unix({{.Stat.ModTime.Unix}}, 0),\n\t\tFileHash:{{printf \"%q\" .Hash}},\n\t\tFileContentType:{{printf \"%q\" .ContentType}},\n\t},\n{{end}}}{{end}}, {{printf \"%#v\" .Dev}}, {{printf \"%#v\" .IsFile}}, {{printf \"%#v\" .Root}})\n{{end}}", &bog.FileInfo{
 	FileName:"main.go.tmpl", 
-	FileSize:940, 
+	FileSize:2531, 
 	FileMode:0x1a4, 
-	FileModTime:time.Unix(1413281460, 0),
+	FileModTime:time.Unix(1792425553, 0),
+	FileHash:"4cd5c146c5bd4f26a5520684730cdc45eb0522cc83bcdee4fd882ee25f22e964",
+	FileContentType:"text/plain; charset=utf-8",
 })
 
 
 
-var vvv1413281467Templates = bog.NewBogFolder([]bog.File{vvv1413281467TemplatesMainGoTmpl,}, &bog.FileInfo{
+var vvvTemplatesarchive = bog.NewBogFolder([]bog.File{vvvTemplatesarchiveMainGoTmpl,}, &bog.FileInfo{
         FileName:"templates",
-	FileSize:102,
-        FileMode:0x800001ed,
-	FileModTime:time.Unix(1413281460, 0),
+	FileSize:4096,
+        FileMode:0x800001fd,
+	FileModTime:time.Unix(1792425553, 0),
 })
 
 
 
 // TemplatesArchive is archived variable for 'templates'
 var TemplatesArchive = bog.NewArchive(map[string]bog.File{
-	"/main.go.tmpl": vvv1413281467TemplatesMainGoTmpl,
-	"/": vvv1413281467Templates,
+	"/main.go.tmpl": vvvTemplatesarchiveMainGoTmpl,
+	"/": vvvTemplatesarchive,
 	
 }, false, false, "templates")
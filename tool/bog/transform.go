@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/keimoon/bog"
+)
+
+// transform changes the content of the files matched by Glob. Command is the name of a
+// built-in transform, or a shell command reading the content from stdin and writing the result to stdout.
+type transform struct {
+	Glob    string `json:"glob"`
+	Command string `json:"command"`
+}
+
+// builtinTransforms hold the transforms available by name.
+var builtinTransforms = map[string]func(data []byte) ([]byte, error){
+	"minify-css":    minifyCSS,
+	"minify-json":   minifyJSON,
+	"normalize-eol": normalizeEOL,
+}
+
+// transformFile applies the transforms of p matching the file, in order.
+// The size recorded for a transformed file is the size of the result.
+func transformFile(p *params, fileVar *FileVar) error {
+	transformed := false
+	for _, t := range p.Transforms {
+		if !matchGlob(t.Glob, fileVar.Path) {
+			continue
+		}
+		data, err := t.apply(fileVar)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %v", sourceName(p, fileVar), t.Command, err)
+		}
+		fileVar.Data = data
+		transformed = true
+	}
+	if transformed {
+		fileVar.Stat = &bog.FileInfo{
+			FileName:    fileVar.Stat.Name(),
+			FileSize:    int64(len(fileVar.Data)),
+			FileMode:    fileVar.Stat.Mode(),
+			FileModTime: fileVar.Stat.ModTime(),
+		}
+	}
+	return nil
+}
+
+func (t *transform) apply(fileVar *FileVar) ([]byte, error) {
+	if builtin, ok := builtinTransforms[t.Command]; ok {
+		return builtin(fileVar.Data)
+	}
+	output, err := runCommand(t.Command, fileVar, bytes.NewReader(fileVar.Data))
+	if err != nil {
+		if out := bytes.TrimSpace(output); len(out) > 0 {
+			return nil, fmt.Errorf("%v\n\t%s", err, bytes.Replace(out, []byte("\n"), []byte("\n\t"), -1))
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+func minifyJSON(data []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := json.Compact(buf, data)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// minifyCSS removes comments, collapses whitespace, and removes whitespace around braces,
+// semicolons and commas. Strings are kept as is.
+func minifyCSS(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	space := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 3
+			space = true
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(data) && data[end] != c {
+				if data[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(data) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			out = appendCSSSpace(out, space, c)
+			out = append(out, data[i:end+1]...)
+			i = end
+			space = false
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space = true
+		default:
+			if c == '}' && len(out) > 0 && out[len(out)-1] == ';' {
+				out = out[:len(out)-1]
+			}
+			out = appendCSSSpace(out, space, c)
+			out = append(out, c)
+			space = false
+		}
+	}
+	return out, nil
+}
+
+// appendCSSSpace appends the whitespace found before c, unless it is useless.
+func appendCSSSpace(out []byte, space bool, c byte) []byte {
+	if !space || len(out) == 0 || isCSSPunct(c) || isCSSPunct(out[len(out)-1]) {
+		return out
+	}
+	return append(out, ' ')
+}
+
+func isCSSPunct(c byte) bool {
+	return c == '{' || c == '}' || c == ';' || c == ','
+}
+
+// normalizeEOL replaces CRLF and CR line endings with LF.
+func normalizeEOL(data []byte) ([]byte, error) {
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	return bytes.Replace(data, []byte("\r"), []byte("\n"), -1), nil
+}
@@ -1,7 +1,15 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -28,3 +36,129 @@ var slugRegex = regexp.MustCompile("(\\s|-|_)+")
 func makePublicVariableName(name string) string {
 	return strings.Replace(strings.Title(slugRegex.ReplaceAllString(name, " ")), " ", "", -1)
 }
+
+// ignoreFile is a flag reading ignore rules from files in .bogignore format
+type ignoreFile []string
+
+func (i *ignoreFile) String() string {
+	return strings.Join(*i, ",")
+}
+
+func (i *ignoreFile) Set(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		*i = append(*i, scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// checkFlags is a flag holding commands by glob, set with glob=command values
+type checkFlags map[string]string
+
+func (c *checkFlags) String() string {
+	pairs := []string{}
+	for glob, command := range *c {
+		pairs = append(pairs, glob+"="+command)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (c *checkFlags) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i <= 0 {
+		return errors.New("expected glob=command")
+	}
+	if _, err := path.Match(value[:i], ""); err != nil {
+		return err
+	}
+	if *c == nil {
+		*c = make(checkFlags)
+	}
+	(*c)[value[:i]] = value[i+1:]
+	return nil
+}
+
+// matchGlob reports whether the archived file path matches glob. Globs without slash are matched
+// against the base name of the file, other globs against its path relative to the root folder.
+func matchGlob(glob string, archivePath string) bool {
+	name := strings.TrimPrefix(archivePath, "/")
+	if !strings.Contains(glob, "/") {
+		name = path.Base(archivePath)
+	}
+	matched, _ := path.Match(strings.TrimPrefix(glob, "/"), name)
+	return matched
+}
+
+// transformFlags is a flag holding the transforms of archived files, set with glob=transform values
+type transformFlags []*transform
+
+func (t *transformFlags) String() string {
+	pairs := []string{}
+	for _, tr := range *t {
+		pairs = append(pairs, tr.Glob+"="+tr.Command)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (t *transformFlags) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i <= 0 || i == len(value)-1 {
+		return errors.New("expected glob=transform")
+	}
+	if _, err := path.Match(value[:i], ""); err != nil {
+		return err
+	}
+	*t = append(*t, &transform{Glob: value[:i], Command: value[i+1:]})
+	return nil
+}
+
+// sizeFlag is a flag holding a size in bytes, set with values like 500000, 512KB or 50MB
+type sizeFlag int64
+
+func (s *sizeFlag) String() string {
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+func (s *sizeFlag) Set(value string) error {
+	units := []struct {
+		suffix string
+		size   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}}
+	number := strings.ToUpper(strings.TrimSpace(value))
+	unit := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(number, u.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(number, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid size %q", value)
+	}
+	*s = sizeFlag(n * unit)
+	return nil
+}
+
+// UnmarshalJSON accepts sizes as numbers of bytes, or as strings like flag values.
+func (s *sizeFlag) UnmarshalJSON(b []byte) error {
+	var value interface{}
+	err := json.Unmarshal(b, &value)
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case float64:
+		return s.Set(strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		return s.Set(v)
+	}
+	return fmt.Errorf("invalid size %s", b)
+}
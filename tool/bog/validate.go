@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// validationError lists the problems found in archived files, one per line, as file:line: message.
+type validationError []string
+
+func (e validationError) Error() string {
+	return strings.Join(e, "\n")
+}
+
+// validators hold the built-in checks by file extension. They return the line of the problem, or 0 if unknown.
+var validators = map[string]func(data []byte) (int, error){
+	".tmpl": validateTemplate,
+	".html": validateTemplate,
+	".json": validateJSON,
+	".xml":  validateXML,
+}
+
+// validateFile runs the built-in checks if p.Validate is set, and the check commands of p on the file.
+// It returns the problems found.
+func validateFile(p *params, fileVar *FileVar) validationError {
+	var errs validationError
+	name := sourceName(p, fileVar)
+	if check, ok := validators[strings.ToLower(path.Ext(fileVar.Path))]; ok && p.Validate {
+		line, err := check(fileVar.Data)
+		if err != nil {
+			if line > 0 {
+				name = fmt.Sprintf("%s:%d", name, line)
+			}
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	globs := []string{}
+	for glob := range p.Checks {
+		globs = append(globs, glob)
+	}
+	sort.Strings(globs)
+	for _, glob := range globs {
+		if !matchGlob(glob, fileVar.Path) {
+			continue
+		}
+		output, err := runCommand(p.Checks[glob], fileVar, bytes.NewReader(fileVar.Data))
+		if err != nil {
+			msg := fmt.Sprintf("%s: %s: %v", name, p.Checks[glob], err)
+			if out := strings.TrimSpace(string(output)); out != "" {
+				msg += "\n\t" + strings.Replace(out, "\n", "\n\t", -1)
+			}
+			errs = append(errs, msg)
+		}
+	}
+	return errs
+}
+
+// sourceName returns the name of the archived file in messages: its path for a folder source, or
+// the path of the archived entry following the name of a tar or zip source.
+func sourceName(p *params, fileVar *FileVar) string {
+	if fileVar.Origin != "" {
+		return fileVar.Origin
+	}
+	if isArchiveSource(p.Source) {
+		return p.Source + ":" + fileVar.Path
+	}
+	if fileVar.Path == "/" {
+		return p.Source
+	}
+	return filepath.Join(p.Source, filepath.FromSlash(fileVar.Path))
+}
+
+// runCommand runs command with the shell, reading from stdin. BOG_PATH holds the archived path of fileVar.
+// It returns the standard output, or the combined output if the command fails.
+func runCommand(command string, fileVar *FileVar, stdin io.Reader) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(), "BOG_PATH="+fileVar.Path)
+	cmd.Stdin = stdin
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err != nil {
+		return append(stderr.Bytes(), stdout.Bytes()...), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// validateTemplate checks the template syntax of data, which text/template and html/template share.
+// Functions are not checked, because they are only known by the program using the templates, and
+// neither are the contexts escaped by html/template, which are only known when executing them.
+func validateTemplate(data []byte) (int, error) {
+	tree := parse.New("")
+	tree.Mode = parse.SkipFuncCheck
+	_, err := tree.Parse(string(data), "", "", map[string]*parse.Tree{})
+	if err != nil {
+		// Messages look like "template: :12: unexpected EOF"
+		msg := strings.TrimPrefix(err.Error(), "template: :")
+		var line int
+		if n, _ := fmt.Sscanf(msg, "%d:", &line); n == 1 {
+			msg = strings.TrimSpace(msg[strings.Index(msg, ":")+1:])
+			return line, fmt.Errorf("%s", msg)
+		}
+		return 0, err
+	}
+	return 0, nil
+}
+
+func validateJSON(data []byte) (int, error) {
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		return lineAt(data, syntaxErr.Offset), err
+	}
+	return 0, err
+}
+
+func validateXML(data []byte) (int, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return 0, nil
+		}
+		if syntaxErr, ok := err.(*xml.SyntaxError); ok {
+			return syntaxErr.Line, fmt.Errorf("%s", syntaxErr.Msg)
+		}
+		if err != nil {
+			return lineAt(data, decoder.InputOffset()), err
+		}
+	}
+}
+
+// lineAt returns the line holding the byte at offset in data, starting from 1.
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
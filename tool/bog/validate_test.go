@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		data string
+		line int
+		ok   bool
+	}{
+		{"{{define \"a\"}}{{upper .Name}}{{end}}", 0, true},
+		// html/template contexts are only checked when executing templates.
+		{"<a href=\"{{.URL}}\">", 0, true},
+		{"line 1\n{{if .X}}\nline 3", 3, false},
+		{"\n{{.X", 2, false},
+	}
+	for _, test := range tests {
+		line, err := validateTemplate([]byte(test.data))
+		if (err == nil) != test.ok || line != test.line {
+			t.Errorf("validateTemplate(%q) = %d, %v, expected line %d", test.data, line, err, test.line)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Watch regenerates an archive, or the archives of the project config, when their sources change.
+// An interrupt stops watching once the current generation, if any, is finished.
+func Watch() int {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	interval := flags.Duration("interval", 500*time.Millisecond, "Time between two scans of the sources")
+	debounce := flags.Duration("debounce", 300*time.Millisecond, "Time without change before regenerating")
+	flags.Parse(Args[1:])
+	var allParams []*params
+	switch {
+	case flags.NArg() == 1:
+		allParams = []*params{newParams(flags.Arg(0))}
+	case flags.NArg() == 0:
+		if _, err := os.Stat(projectConfig); err != nil {
+			Usage()
+			return 2
+		}
+		var err error
+		allParams, err = loadConfig(projectConfig)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	default:
+		Usage()
+		return 2
+	}
+	for _, p := range allParams {
+		if p.Source == stdinSource {
+			fmt.Fprintln(os.Stderr, "cannot watch stdin")
+			return 2
+		}
+	}
+	snapshots := make([]string, len(allParams))
+	for i, p := range allParams {
+		snapshots[i] = snapshot(p)
+		regenerate(p)
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	changed := make([]time.Time, len(allParams))
+	for {
+		select {
+		case <-interrupt:
+			return 0
+		case <-ticker.C:
+		}
+		for i, p := range allParams {
+			snap := snapshot(p)
+			if snap != snapshots[i] {
+				snapshots[i] = snap
+				changed[i] = time.Now()
+				continue
+			}
+			if !changed[i].IsZero() && time.Since(changed[i]) >= *debounce {
+				changed[i] = time.Time{}
+				regenerate(p)
+			}
+		}
+	}
+}
+
+// regenerate generates the archive described by p, and writes the outputs whose content changed.
+// Errors are printed, so that watching goes on.
+func regenerate(p *params) {
+	written, ch, err := generate(p)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", p.Output, err)
+		return
+	}
+	ch.print(os.Stdout, p.Output)
+	for _, name := range written {
+		if name != p.Output {
+			fmt.Println(name)
+		}
+	}
+}
+
+// snapshot describes the names, sizes, modes and modification times of the files archived by p,
+// and of the .bogignore and config files read when archiving them. Ignored files are left out.
+func snapshot(p *params) string {
+	sources := []string{p.Source}
+	if len(p.Mounts) > 0 {
+		sources = nil
+		for _, m := range p.Mounts {
+			sources = append(sources, m.Source)
+		}
+	}
+	output, _ := filepath.Abs(p.Output)
+	var b strings.Builder
+	describe := func(name string, info os.FileInfo) {
+		b.WriteString(name + "\x00" + strconv.FormatInt(info.Size(), 10) + "\x00" + info.Mode().String() + "\x00" + strconv.FormatInt(info.ModTime().UnixNano(), 10) + "\n")
+	}
+	for _, source := range sources {
+		for _, name := range configFiles {
+			if info, err := os.Stat(filepath.Join(source, name)); err == nil {
+				describe(filepath.Join(source, name), info)
+			}
+		}
+		err := walk(source, func(path string, info os.FileInfo, children []string) error {
+			describe(path, info)
+			if info.IsDir() {
+				if ignore, err := os.Stat(filepath.Join(path, ".bogignore")); err == nil {
+					describe(filepath.Join(path, ".bogignore"), ignore)
+				}
+			}
+			return nil
+		}, ignoreRules(p.Ignore), output)
+		if err != nil {
+			b.WriteString(err.Error() + "\n")
+		}
+	}
+	return b.String()
+}